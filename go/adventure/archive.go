@@ -0,0 +1,135 @@
+package adventure
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Magic numbers identifying compressed game databases
+var (
+	gzipMagic     = []byte{0x1f, 0x8b}
+	zipMagic      = []byte("PK\x03\x04")
+	emptyZipMagic = []byte("PK\x05\x06") // An archive with no files starts at its directory end
+)
+
+// maxArchiveDepth limits how many layers of compression are unwrapped,
+// e.g. a gzip-compressed zip bundle
+const maxArchiveDepth = 4
+
+// unwrapArchive strips any gzip or zip wrapping from a database and returns
+// the plain text. member names the database to use inside a zip archive.
+func unwrapArchive(data []byte, member string) ([]byte, error) {
+	for depth := 0; depth < maxArchiveDepth; depth++ {
+		var err error
+
+		switch {
+		case bytes.HasPrefix(data, gzipMagic):
+			data, err = readGzip(data)
+		case bytes.HasPrefix(data, zipMagic), bytes.HasPrefix(data, emptyZipMagic):
+			data, err = readZipMember(data, member)
+		default:
+			return data, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("game file is nested in too many archives")
+}
+
+// readGzip decompresses a gzip stream
+func readGzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open gzip data: %w", err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress gzip data: %w", err)
+	}
+
+	return content, nil
+}
+
+// readZipMember extracts a single database from a zip archive. If member is
+// empty the archive must contain exactly one .dat file (or exactly one file).
+func readZipMember(data []byte, member string) ([]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip archive: %w", err)
+	}
+
+	file, err := selectZipMember(archive, member)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s in zip archive: %w", file.Name, err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s in zip archive: %w", file.Name, err)
+	}
+
+	return content, nil
+}
+
+// selectZipMember picks the game database from the files in an archive
+func selectZipMember(archive *zip.Reader, member string) (*zip.File, error) {
+	var files, databases []*zip.File
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		files = append(files, file)
+
+		ext := strings.ToLower(path.Ext(file.Name))
+		if ext == ".dat" || ext == ".gz" {
+			databases = append(databases, file)
+		}
+	}
+
+	// A named member may be given with or without its directory
+	if member != "" {
+		for _, file := range files {
+			if file.Name == member || path.Base(file.Name) == member {
+				return file, nil
+			}
+		}
+		return nil, fmt.Errorf("zip archive does not contain %s", member)
+	}
+
+	switch {
+	case len(databases) == 1:
+		return databases[0], nil
+	case len(databases) == 0 && len(files) == 1:
+		return files[0], nil
+	case len(databases) == 0 && len(files) == 0:
+		return nil, fmt.Errorf("zip archive is empty")
+	}
+
+	// Ambiguous - the caller has to name the database
+	candidates := databases
+	if len(candidates) == 0 {
+		candidates = files
+	}
+	names := make([]string, len(candidates))
+	for i, file := range candidates {
+		names[i] = file.Name
+	}
+	return nil, fmt.Errorf("zip archive contains several game files, choose one of: %s",
+		strings.Join(names, ", "))
+}
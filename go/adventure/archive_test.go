@@ -0,0 +1,94 @@
+package adventure
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+)
+
+// gzipData compresses data with gzip
+func gzipData(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// zipData builds a zip archive holding the given files, in order
+func zipData(t *testing.T, files ...struct {
+	name string
+	data []byte
+}) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := writer.Create(file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(file.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLoadArchives(t *testing.T) {
+	state, cave := loadTestGame(t, "cave.dat")
+	want := writeDat(t, state)
+
+	type file = struct {
+		name string
+		data []byte
+	}
+	other := []byte("not a game")
+	several := zipData(t, file{"games/cave.dat", cave}, file{"games/other.dat", other}, file{"README", other})
+
+	for _, test := range []struct {
+		name   string
+		data   []byte
+		member string
+		err    string // Expected error text, or empty to load the cave
+	}{
+		{"plain", cave, "", ""},
+		{"gzip", gzipData(t, cave), "", ""},
+		{"zip with one database", zipData(t, file{"README", other}, file{"cave.dat", cave}), "", ""},
+		{"zip with one file", zipData(t, file{"cave.txt", cave}), "", ""},
+		{"zip holding a gzip database", zipData(t, file{"cave.dat.gz", gzipData(t, cave)}), "", ""},
+		{"gzip of a zip", gzipData(t, zipData(t, file{"cave.dat", cave})), "", ""},
+		{"several databases", several, "", "several game files, choose one of: games/cave.dat, games/other.dat"},
+		{"member by path", several, "games/cave.dat", ""},
+		{"member by base name", several, "cave.dat", ""},
+		{"missing member", several, "lost.dat", "does not contain lost.dat"},
+		{"empty zip", zipData(t), "", "zip archive is empty"},
+		{"several other files", zipData(t, file{"a.txt", cave}, file{"b.txt", cave}), "", "choose one of: a.txt, b.txt"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			state, err := LoadGameDataReader(bytes.NewReader(test.data), LoadOptions{Member: test.member})
+			switch {
+			case test.err != "":
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("got error %v, want one containing %q", err, test.err)
+				}
+			case err != nil:
+				t.Fatal(err)
+			default:
+				if got := writeDat(t, state); !bytes.Equal(want, got) {
+					t.Error("loaded a different game")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,878 @@
+package adventure
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Compile turns ScottKit-style adventure source into a playable game state,
+// doing all the number encoding of the .dat format. The language is the one
+// Decompile writes:
+//
+//	ident 1                      # header settings: ident, version, textsize,
+//	start meadow                 # wordlen, maxload, lighttime, treasures,
+//	treasury meadow              # start, treasury and lightsource
+//	vocab verb 1 GO *WALK        # words placed from a table index; "*" marks
+//	                             # a synonym of the word before it
+//	room meadow "sunny meadow"   # rooms are numbered from 1 in order;
+//		exit north forest        # "room nowhere" describes room 0
+//	item lamp "Brass lamp"       # items are numbered from 0 in order
+//		called LAM
+//		at meadow                # or "carried"; items are nowhere by default
+//	message "Welcome!"           # messages are numbered from 1 in order;
+//	                             # "message 0" sets the text of message 0
+//	action OPEN DOOR when IN/W door and -BIT 1  # title
+//		MSG "The door opens."    # message by text or number
+//		EXx,x door open_door
+//	occur 50% when IN meadow     # automatic action with percent chance
+//	continuation when HAS lamp   # runs after a CONT
+//
+// Vocabulary words used by actions but not declared are added to the
+// tables automatically. A source that declares no vocabulary at all gets
+// the standard words: AUT, GO, GET and DROP, and ANY and the six
+// directions. A condition written as a negative number is stored as that
+// raw value, as some databases hold one. Errors are reported with file and
+// line.
+func Compile(r io.Reader, filename string) (*GameState, error) {
+	c := &compiler{file: filename}
+	if err := c.parse(r); err != nil {
+		return nil, err
+	}
+	if len(c.errs) > 0 {
+		return nil, c.errs
+	}
+
+	game := c.resolve()
+	if len(c.errs) > 0 {
+		return nil, c.errs
+	}
+
+	state, err := FromStructured(game)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return state, nil
+}
+
+// CompileError reports a problem at a position in adventure source
+type CompileError struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *CompileError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// CompileErrors lists every problem found while compiling
+type CompileErrors []*CompileError
+
+func (errs CompileErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Standard vocabulary placed in slots the source does not declare
+var (
+	defaultVerbs = map[int]string{0: "AUT", 1: "GO", 10: "GET", 18: "DROP"}
+	defaultNouns = map[int]string{0: "ANY", 1: "NORTH", 2: "SOUTH", 3: "EAST", 4: "WEST", 5: "UP", 6: "DOWN"}
+)
+
+// Format limits checked by the compiler
+const (
+	maxNounIndex = 149 // Nouns are stored as verb*150 + noun
+	maxRoomIndex = 254 // 255 means carried
+)
+
+// sourceToken is a word or quoted string from a line of source
+type sourceToken struct {
+	text   string
+	quoted bool
+}
+
+// sourceLine is one statement with its position and trailing comment
+type sourceLine struct {
+	line    int
+	tokens  []sourceToken
+	comment string
+}
+
+// sourceOp is a condition or command with unresolved arguments
+type sourceOp struct {
+	line int
+	name string
+	args []sourceToken
+}
+
+type sourceRoom struct {
+	line  int
+	name  string
+	desc  string
+	exits [6]*sourceToken
+}
+
+type sourceItem struct {
+	line    int
+	name    string
+	desc    string
+	autoGet string
+	at      *sourceToken
+}
+
+type sourceVocab struct {
+	line  int
+	index int
+	words []string
+}
+
+type sourceAction struct {
+	line       int
+	title      string
+	verb       *sourceToken // nil for automatic actions
+	noun       *sourceToken
+	chance     int
+	conditions []sourceOp
+	commands   []sourceOp
+}
+
+// compiler collects declarations in a first pass and resolves names after
+type compiler struct {
+	file string
+	errs CompileErrors
+
+	settings  map[string]sourceLine
+	rooms     []*sourceRoom
+	room0     *sourceRoom // Room 0, if the source describes it
+	items     []*sourceItem
+	messages  []string
+	message0  string // Text of message 0, usually empty
+	verbs     []sourceVocab
+	nouns     []sourceVocab
+	actions   []*sourceAction
+	roomIndex map[string]int
+	itemIndex map[string]int
+	verbTable []Word
+	nounTable []Word
+}
+
+// errorf records an error at a source line
+func (c *compiler) errorf(line int, format string, args ...interface{}) {
+	c.errs = append(c.errs, &CompileError{File: c.file, Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+// Header settings, each taking a single value
+var sourceSettings = map[string]bool{
+	"ident": true, "version": true, "textsize": true, "wordlen": true, "maxload": true,
+	"lighttime": true, "treasures": true, "start": true, "treasury": true, "lightsource": true,
+}
+
+// parse reads the source into declarations
+func (c *compiler) parse(r io.Reader) error {
+	c.settings = map[string]sourceLine{}
+
+	var room *sourceRoom
+	var item *sourceItem
+	var action *sourceAction
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		sl, err := splitSourceLine(scanner.Text())
+		if err != nil {
+			c.errorf(lineNumber, "%v", err)
+			continue
+		}
+		sl.line = lineNumber
+		if len(sl.tokens) == 0 {
+			continue
+		}
+
+		keyword := sl.tokens[0].text
+		args := sl.tokens[1:]
+		if sl.tokens[0].quoted {
+			keyword = ""
+		}
+
+		// Statements that start a new block or stand alone
+		switch {
+		case sourceSettings[keyword]:
+			room, item, action = nil, nil, nil
+			if len(args) != 1 {
+				c.errorf(sl.line, "%s needs exactly one value", keyword)
+				continue
+			}
+			if _, dup := c.settings[keyword]; dup {
+				c.errorf(sl.line, "%s is set more than once", keyword)
+			}
+			c.settings[keyword] = sl
+			continue
+		case keyword == "vocab":
+			room, item, action = nil, nil, nil
+			c.parseVocab(sl, args)
+			continue
+		case keyword == "room":
+			item, action = nil, nil
+			room = c.parseRoom(sl, args)
+			continue
+		case keyword == "item":
+			room, action = nil, nil
+			item = c.parseItem(sl, args)
+			continue
+		case keyword == "message" && len(args) == 2 && !args[0].quoted:
+			room, item, action = nil, nil, nil
+			if args[0].text != "0" || !args[1].quoted {
+				c.errorf(sl.line, "only message 0 can be given a number, followed by its quoted text")
+				continue
+			}
+			c.message0 = args[1].text
+			continue
+		case keyword == "message":
+			room, item, action = nil, nil, nil
+			if len(args) != 1 || !args[0].quoted {
+				c.errorf(sl.line, "message needs one quoted text")
+				continue
+			}
+			c.messages = append(c.messages, args[0].text)
+			continue
+		case keyword == "action" || keyword == "occur" || keyword == "continuation":
+			room, item = nil, nil
+			action = c.parseAction(sl, keyword, args)
+			continue
+		}
+
+		// Statements inside a block
+		switch {
+		case room != nil && keyword == "exit":
+			c.parseExit(room, sl, args)
+		case item != nil && keyword == "called":
+			if len(args) != 1 {
+				c.errorf(sl.line, "called needs exactly one word")
+				continue
+			}
+			item.autoGet = args[0].text
+		case item != nil && keyword == "at":
+			if len(args) != 1 {
+				c.errorf(sl.line, "at needs exactly one room")
+				continue
+			}
+			item.at = &args[0]
+		case action != nil:
+			action.commands = append(action.commands, sourceOp{line: sl.line, name: sl.tokens[0].text, args: args})
+		default:
+			c.errorf(sl.line, "unexpected %q", sl.tokens[0].text)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", c.file, err)
+	}
+	return nil
+}
+
+// splitSourceLine breaks a line into words and quoted strings, splitting
+// off a trailing # comment
+func splitSourceLine(text string) (sourceLine, error) {
+	var sl sourceLine
+	for i := 0; i < len(text); {
+		switch ch := text[i]; {
+		case ch == ' ' || ch == '\t' || ch == '\r':
+			i++
+		case ch == '#':
+			sl.comment = strings.TrimSpace(text[i+1:])
+			return sl, nil
+		case ch == '"':
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(text) {
+					return sl, fmt.Errorf("unterminated string")
+				}
+				if text[i] == '"' {
+					i++
+					break
+				}
+				if text[i] == '\\' && i+1 < len(text) {
+					i++
+					switch text[i] {
+					case 'n':
+						b.WriteByte('\n')
+					case 'r':
+						b.WriteByte('\r')
+					default:
+						b.WriteByte(text[i])
+					}
+					i++
+					continue
+				}
+				b.WriteByte(text[i])
+				i++
+			}
+			if strings.Contains(b.String(), "\"") {
+				return sl, fmt.Errorf("text cannot contain a double quote in the .dat format")
+			}
+			sl.tokens = append(sl.tokens, sourceToken{text: b.String(), quoted: true})
+		default:
+			start := i
+			for i < len(text) && !strings.ContainsRune(" \t\r#\"", rune(text[i])) {
+				i++
+			}
+			sl.tokens = append(sl.tokens, sourceToken{text: text[start:i]})
+		}
+	}
+	return sl, nil
+}
+
+// parseVocab reads "vocab verb|noun INDEX WORD..."
+func (c *compiler) parseVocab(sl sourceLine, args []sourceToken) {
+	if len(args) < 3 {
+		c.errorf(sl.line, "vocab needs a table, an index and at least one word")
+		return
+	}
+	index, err := strconv.Atoi(args[1].text)
+	if err != nil || index < 0 {
+		c.errorf(sl.line, "invalid vocabulary index %q", args[1].text)
+		return
+	}
+	decl := sourceVocab{line: sl.line, index: index}
+	for _, word := range args[2:] {
+		decl.words = append(decl.words, word.text)
+	}
+
+	switch args[0].text {
+	case "verb":
+		c.verbs = append(c.verbs, decl)
+	case "noun":
+		c.nouns = append(c.nouns, decl)
+	default:
+		c.errorf(sl.line, "vocab table must be verb or noun, not %q", args[0].text)
+	}
+}
+
+// parseRoom reads "room NAME "description"". The name nowhere stands for
+// room 0.
+func (c *compiler) parseRoom(sl sourceLine, args []sourceToken) *sourceRoom {
+	if len(args) != 2 || args[0].quoted || !args[1].quoted {
+		c.errorf(sl.line, "room needs a name and a quoted description")
+		return nil
+	}
+	room := &sourceRoom{line: sl.line, name: args[0].text, desc: args[1].text}
+	if room.name == "nowhere" {
+		if c.room0 != nil {
+			c.errorf(sl.line, "room nowhere is declared more than once")
+		}
+		c.room0 = room
+		return room
+	}
+	c.rooms = append(c.rooms, room)
+	return room
+}
+
+// parseExit reads "exit DIRECTION ROOM"
+func (c *compiler) parseExit(room *sourceRoom, sl sourceLine, args []sourceToken) {
+	if len(args) != 2 {
+		c.errorf(sl.line, "exit needs a direction and a room")
+		return
+	}
+	for dir, name := range []string{"north", "south", "east", "west", "up", "down"} {
+		if strings.EqualFold(args[0].text, name) {
+			room.exits[dir] = &args[1]
+			return
+		}
+	}
+	c.errorf(sl.line, "unknown direction %q", args[0].text)
+}
+
+// parseItem reads "item NAME "description""
+func (c *compiler) parseItem(sl sourceLine, args []sourceToken) *sourceItem {
+	if len(args) != 2 || args[0].quoted || !args[1].quoted {
+		c.errorf(sl.line, "item needs a name and a quoted description")
+		return nil
+	}
+	item := &sourceItem{line: sl.line, name: args[0].text, desc: args[1].text}
+	c.items = append(c.items, item)
+	return item
+}
+
+// parseAction reads the first line of an action, occur or continuation
+func (c *compiler) parseAction(sl sourceLine, keyword string, args []sourceToken) *sourceAction {
+	action := &sourceAction{line: sl.line, title: sl.comment}
+
+	// Split off the conditions after "when"
+	var conds []sourceToken
+	for i, tok := range args {
+		if !tok.quoted && tok.text == "when" {
+			conds = args[i+1:]
+			args = args[:i]
+			break
+		}
+	}
+
+	switch keyword {
+	case "action":
+		if len(args) < 1 || len(args) > 2 {
+			c.errorf(sl.line, "action needs a verb and an optional noun")
+			return nil
+		}
+		action.verb = &args[0]
+		if len(args) == 2 {
+			action.noun = &args[1]
+		}
+	case "occur":
+		action.chance = 100
+		if len(args) > 1 {
+			c.errorf(sl.line, "occur takes only a percent chance")
+			return nil
+		}
+		if len(args) == 1 {
+			chance, err := strconv.Atoi(strings.TrimSuffix(args[0].text, "%"))
+			if err != nil || chance < 1 || chance > 100 {
+				c.errorf(sl.line, "invalid chance %q (1%% to 100%%)", args[0].text)
+				return nil
+			}
+			action.chance = chance
+		}
+	case "continuation":
+		if len(args) > 0 {
+			c.errorf(sl.line, "continuation takes no verb or noun")
+			return nil
+		}
+	}
+
+	// Conditions are joined with "and"
+	var op *sourceOp
+	for _, tok := range conds {
+		switch {
+		case !tok.quoted && tok.text == "and":
+			op = nil
+		case op == nil:
+			action.conditions = append(action.conditions, sourceOp{line: sl.line, name: tok.text})
+			op = &action.conditions[len(action.conditions)-1]
+		default:
+			op.args = append(op.args, tok)
+		}
+	}
+
+	c.actions = append(c.actions, action)
+	return action
+}
+
+// resolve turns the declarations into the structured form of the game
+func (c *compiler) resolve() *StructuredGame {
+	game := &StructuredGame{}
+
+	c.resolveItems()
+	c.roomIndex = map[string]int{}
+	for i, room := range c.rooms {
+		if _, dup := c.roomIndex[room.name]; dup {
+			c.errorf(room.line, "room %s is declared more than once", room.name)
+		}
+		c.roomIndex[room.name] = i + 1
+	}
+	if len(c.rooms) > maxRoomIndex {
+		c.errorf(c.rooms[maxRoomIndex].line, "game has %d rooms; the format allows %d", len(c.rooms), maxRoomIndex)
+	}
+
+	// The standard words only fill a vocabulary left out altogether, so
+	// a declared vocabulary keeps exactly the slots it gives
+	verbDefaults, nounDefaults := defaultVerbs, defaultNouns
+	if len(c.verbs) > 0 || len(c.nouns) > 0 {
+		verbDefaults, nounDefaults = nil, nil
+	}
+	c.verbTable = c.buildTable(c.verbs, verbDefaults, "verb")
+	c.nounTable = c.buildTable(c.nouns, nounDefaults, "noun")
+
+	// Rooms, after the storeroom 0
+	room0 := c.room0
+	if room0 == nil {
+		room0 = &sourceRoom{}
+	}
+	for _, room := range append([]*sourceRoom{room0}, c.rooms...) {
+		var exits [6]int
+		for dir, ref := range room.exits {
+			if ref != nil {
+				exits[dir] = c.roomRef(room.line, *ref)
+			}
+		}
+		game.Rooms = append(game.Rooms, StructuredRoom{
+			Description: room.desc,
+			Exits: StructuredExits{
+				North: exits[NORTH], South: exits[SOUTH], East: exits[EAST],
+				West: exits[WEST], Up: exits[UP], Down: exits[DOWN],
+			},
+		})
+	}
+
+	// Items
+	treasures := 0
+	for _, item := range c.items {
+		location := DESTROYED
+		if item.at != nil {
+			location = c.roomRef(item.line, *item.at)
+		}
+		if strings.HasPrefix(item.desc, "*") {
+			treasures++
+		}
+		game.Items = append(game.Items, StructuredItem{
+			Description: item.desc,
+			AutoGet:     item.autoGet,
+			Location:    location,
+		})
+	}
+	if len(game.Items) == 0 {
+		game.Items = append(game.Items, StructuredItem{})
+	}
+
+	// Messages, after message 0
+	game.Messages = append([]string{c.message0}, c.messages...)
+
+	// Actions
+	for _, action := range c.actions {
+		sa := c.resolveAction(action, game)
+		if _, err := encodeStructuredAction(sa); err != nil {
+			c.errorf(action.line, "%v", err)
+		}
+		game.Actions = append(game.Actions, sa)
+	}
+	if len(game.Actions) == 0 {
+		game.Actions = append(game.Actions, StructuredAction{})
+	}
+	if len(game.Messages)-1 > MaxMessage {
+		c.errorf(c.actionLine(), "game has %d messages; the format allows %d", len(game.Messages)-1, MaxMessage)
+	}
+
+	// Vocabulary, with any words added by actions
+	for len(c.verbTable) < len(c.nounTable) {
+		c.verbTable = append(c.verbTable, Word{})
+	}
+	for len(c.nounTable) < len(c.verbTable) {
+		c.nounTable = append(c.nounTable, Word{})
+	}
+	for i := range c.verbTable {
+		game.Verbs = append(game.Verbs, encodeWord(c.verbTable[i]))
+		game.Nouns = append(game.Nouns, encodeWord(c.nounTable[i]))
+	}
+	if len(c.nounTable)-1 > maxNounIndex {
+		c.errorf(c.actionLine(), "game has %d vocabulary entries; nouns are limited to %d", len(c.nounTable)-1, maxNounIndex)
+	}
+
+	// Header settings, with defaults for anything left out
+	game.Header = StructuredHeader{
+		AdventureNumber:  c.number("ident", 0),
+		AdventureVersion: c.number("version", 0),
+		WordLength:       c.number("wordlen", 3),
+		MaxCarry:         c.number("maxload", 6),
+		LightTime:        c.number("lighttime", 0),
+		Treasures:        c.number("treasures", treasures),
+		PlayerRoom:       c.roomSetting("start", 1),
+	}
+	game.Header.TreasureRoom = c.roomSetting("treasury", game.Header.PlayerRoom)
+	game.Header.TextStorageBytes = c.number("textsize", textSize(game))
+
+	return game
+}
+
+// resolveItems numbers the items, moving the light source to item 9
+func (c *compiler) resolveItems() {
+	c.itemIndex = map[string]int{}
+	for i, item := range c.items {
+		if _, dup := c.itemIndex[item.name]; dup {
+			c.errorf(item.line, "item %s is declared more than once", item.name)
+		}
+		c.itemIndex[item.name] = i
+	}
+
+	sl, ok := c.settings["lightsource"]
+	if !ok {
+		return
+	}
+	index, ok := c.itemIndex[sl.tokens[1].text]
+	if !ok {
+		c.errorf(sl.line, "unknown item %q", sl.tokens[1].text)
+		return
+	}
+	for len(c.items) <= LIGHT_SOURCE {
+		c.items = append(c.items, &sourceItem{name: fmt.Sprintf("item%d", len(c.items))})
+	}
+	c.items[index], c.items[LIGHT_SOURCE] = c.items[LIGHT_SOURCE], c.items[index]
+	for i, item := range c.items {
+		c.itemIndex[item.name] = i
+	}
+}
+
+// buildTable places declared words in a vocabulary table and fills any
+// of the given standard slots that were not declared
+func (c *compiler) buildTable(decls []sourceVocab, defaults map[int]string, wordType string) []Word {
+	var table []Word
+	declared := map[int]bool{}
+	place := func(index int, text string) {
+		for len(table) <= index {
+			table = append(table, Word{Type: wordType, Index: len(table)})
+		}
+		table[index] = parseWord(text, wordType, index, table)
+		declared[index] = true
+	}
+
+	for _, decl := range decls {
+		for n, text := range decl.words {
+			index := decl.index + n
+			if declared[index] {
+				c.errorf(decl.line, "vocabulary slot %d is declared more than once", index)
+			}
+			place(index, text)
+		}
+	}
+	for index := 0; index <= 18; index++ {
+		if text, ok := defaults[index]; ok && !declared[index] && (index >= len(table) || table[index].Word == "") {
+			place(index, text)
+		}
+	}
+
+	return table
+}
+
+// resolveAction resolves the names in one action
+func (c *compiler) resolveAction(action *sourceAction, game *StructuredGame) StructuredAction {
+	sa := StructuredAction{Title: action.title, Noun: action.chance}
+	if action.verb != nil {
+		sa.Verb = c.wordRef(action.line, *action.verb, &c.verbTable, "verb")
+		sa.Noun = 0
+		if action.noun != nil {
+			sa.Noun = c.wordRef(action.line, *action.noun, &c.nounTable, "noun")
+		}
+	}
+
+	for _, op := range action.conditions {
+		// A raw condition value, which has no symbol
+		if value, err := strconv.Atoi(op.name); err == nil {
+			if value >= 0 {
+				c.errorf(op.line, "condition %d: only negative values are given by number", value)
+			} else if len(op.args) > 0 {
+				c.errorf(op.line, "condition %d takes no arguments", value)
+			}
+			sa.Conditions = append(sa.Conditions, StructuredOp{Op: "UNDEFINED", Code: value})
+			continue
+		}
+		code, ok := conditionByName(op.name)
+		if !ok {
+			c.errorf(op.line, "unknown condition %q", op.name)
+			continue
+		}
+		if code == CondPAR {
+			c.errorf(op.line, "PAR is implied by command parameters")
+			continue
+		}
+		so := StructuredOp{Op: Conditions[code].Name}
+		c.resolveArgs(op, Conditions[code].Args, &so, game)
+		sa.Conditions = append(sa.Conditions, so)
+	}
+
+	for _, op := range action.commands {
+		var so StructuredOp
+		var args []ArgKind
+		if code, err := strconv.Atoi(op.name); err == nil {
+			// A command given by number, for duplicate or undefined codes
+			if code < CmdFirstOpcode || code > CmdLastOpcode {
+				c.errorf(op.line, "command code %d is not in the range %d-%d", code, CmdFirstOpcode, CmdLastOpcode)
+				continue
+			}
+			so.Op = "UNDEFINED"
+			if opcode, ok := Commands[code]; ok {
+				so.Op, args = opcode.Name, opcode.Args
+			}
+			so.Code = code
+		} else if strings.EqualFold(op.name, MessageOpcode.Name) {
+			so.Op, args = MessageOpcode.Name, MessageOpcode.Args
+		} else {
+			code, ok := commandByName(op.name)
+			if !ok {
+				c.errorf(op.line, "unknown command %q", op.name)
+				continue
+			}
+			so.Op, args = Commands[code].Name, Commands[code].Args
+		}
+		c.resolveArgs(op, args, &so, game)
+		sa.Commands = append(sa.Commands, so)
+	}
+
+	return sa
+}
+
+// conditionByName finds a condition symbol ignoring case
+func conditionByName(name string) (int, bool) {
+	for code, op := range Conditions {
+		if strings.EqualFold(op.Name, name) {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// commandByName finds a command symbol ignoring case
+func commandByName(name string) (int, bool) {
+	for code := CmdFirstOpcode; code <= CmdLastOpcode; code++ {
+		if op, ok := Commands[code]; ok && strings.EqualFold(op.Name, name) {
+			if primary, ok := CommandCode(op.Name); ok {
+				return primary, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// resolveArgs resolves the arguments of a condition or command
+func (c *compiler) resolveArgs(op sourceOp, kinds []ArgKind, so *StructuredOp, game *StructuredGame) {
+	if len(op.args) != len(kinds) {
+		c.errorf(op.line, "%s takes %d argument(s), got %d", op.name, len(kinds), len(op.args))
+		return
+	}
+	for n, kind := range kinds {
+		arg := op.args[n]
+		var value int
+		switch kind {
+		case ArgItem:
+			value = c.itemRef(op.line, arg)
+		case ArgRoom, ArgPlace:
+			value = c.roomRef(op.line, arg)
+		case ArgMessage:
+			value = c.messageRef(op.line, arg, game)
+		default:
+			value = c.numberRef(op.line, arg)
+		}
+		so.setArg(kind, n, value)
+	}
+}
+
+// roomRef resolves a room name, number, "nowhere" or "carried"
+func (c *compiler) roomRef(line int, tok sourceToken) int {
+	switch tok.text {
+	case "nowhere":
+		return DESTROYED
+	case "carried":
+		return CARRIED
+	}
+	if value, err := strconv.Atoi(tok.text); err == nil {
+		return value
+	}
+	if index, ok := c.roomIndex[tok.text]; ok {
+		return index
+	}
+	c.errorf(line, "unknown room %q", tok.text)
+	return 0
+}
+
+// itemRef resolves an item name or number
+func (c *compiler) itemRef(line int, tok sourceToken) int {
+	if value, err := strconv.Atoi(tok.text); err == nil {
+		return value
+	}
+	if index, ok := c.itemIndex[tok.text]; ok {
+		return index
+	}
+	c.errorf(line, "unknown item %q", tok.text)
+	return 0
+}
+
+// messageRef resolves a message number or text, adding new texts to the
+// message table
+func (c *compiler) messageRef(line int, tok sourceToken, game *StructuredGame) int {
+	if !tok.quoted {
+		return c.numberRef(line, tok)
+	}
+	for i := 1; i < len(game.Messages); i++ {
+		if game.Messages[i] == tok.text {
+			return i
+		}
+	}
+	game.Messages = append(game.Messages, tok.text)
+	return len(game.Messages) - 1
+}
+
+// numberRef resolves a plain number
+func (c *compiler) numberRef(line int, tok sourceToken) int {
+	value, err := strconv.Atoi(tok.text)
+	if err != nil {
+		c.errorf(line, "expected a number, got %q", tok.text)
+	}
+	return value
+}
+
+// wordRef resolves a vocabulary word or number, adding unknown words to
+// the first free slot of the table
+func (c *compiler) wordRef(line int, tok sourceToken, table *[]Word, wordType string) int {
+	if value, err := strconv.Atoi(tok.text); err == nil {
+		return value
+	}
+	for i, w := range *table {
+		if i > 0 && w.Word != "" && strings.EqualFold(w.Word, tok.text) {
+			return w.Index
+		}
+	}
+
+	index := 1
+	for index < len(*table) && ((*table)[index].Word != "" || isReservedSlot(wordType, index)) {
+		index++
+	}
+	for len(*table) <= index {
+		*table = append(*table, Word{Type: wordType, Index: len(*table)})
+	}
+	(*table)[index] = Word{Word: strings.ToUpper(tok.text), Type: wordType, Index: index}
+	return index
+}
+
+// isReservedSlot reports whether a vocabulary slot has a fixed meaning
+func isReservedSlot(wordType string, index int) bool {
+	if wordType == "verb" {
+		_, ok := defaultVerbs[index]
+		return ok
+	}
+	_, ok := defaultNouns[index]
+	return ok
+}
+
+// number returns a numeric header setting
+func (c *compiler) number(name string, def int) int {
+	sl, ok := c.settings[name]
+	if !ok {
+		return def
+	}
+	return c.numberRef(sl.line, sl.tokens[1])
+}
+
+// roomSetting returns a header setting that names a room
+func (c *compiler) roomSetting(name string, def int) int {
+	sl, ok := c.settings[name]
+	if !ok {
+		return def
+	}
+	return c.roomRef(sl.line, sl.tokens[1])
+}
+
+// actionLine returns a line to report game-wide limits against
+func (c *compiler) actionLine() int {
+	if len(c.actions) > 0 {
+		return c.actions[len(c.actions)-1].line
+	}
+	return 1
+}
+
+// textSize estimates the text storage header value from the game's text
+func textSize(game *StructuredGame) int {
+	size := 0
+	for _, room := range game.Rooms {
+		size += len(room.Description) + 1
+	}
+	for _, item := range game.Items {
+		size += len(item.Description) + len(item.AutoGet) + 1
+	}
+	for _, msg := range game.Messages {
+		size += len(msg) + 1
+	}
+	return size
+}
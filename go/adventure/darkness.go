@@ -0,0 +1,101 @@
+package adventure
+
+import (
+	"fmt"
+)
+
+// DarknessRules decide what happens to a player without light. The zero
+// value is harmless: moves in the dark are never fatal and nothing is
+// hidden from GET.
+type DarknessRules struct {
+	PitChance       int    // Percent chance that moving in the dark is fatal; 0 turns the pit off
+	PitBlockedOnly  bool   // Only a move with no exit that way can be fatal
+	Warning         string // Printed before every move in the dark; empty for none
+	Blind           bool   // GET cannot take items the player cannot see
+	LampCarriedOnly bool   // The light source only lights the room when carried, not when left there
+}
+
+// Standard darkness rule sets
+var (
+	// StandardDarkness is the engine's own behaviour: any move in the dark
+	// has a one in four chance of ending in a pit
+	StandardDarkness = DarknessRules{PitChance: 25}
+
+	// StrictDarkness warns about every move in the dark, kills the player
+	// who walks into a wall and does not let them take what they cannot see
+	StrictDarkness = DarknessRules{
+		PitChance:      100,
+		PitBlockedOnly: true,
+		Warning:        "Dangerous to move in the dark!",
+		Blind:          true,
+	}
+
+	// SafeDarkness only hides the room
+	SafeDarkness = DarknessRules{}
+)
+
+// DarknessRulesets names the standard darkness rules, for selecting them
+// from the command line
+var DarknessRulesets = map[string]DarknessRules{
+	"standard": StandardDarkness,
+	"strict":   StrictDarkness,
+	"safe":     SafeDarkness,
+}
+
+// IsDark reports whether the player is in darkness: the dark flag is set
+// and the light source is not lighting the room
+func IsDark(state *GameState) bool {
+	if state.BitFlags&(1<<DARKBIT) == 0 {
+		return false
+	}
+
+	// A game without a light source has nothing to light the dark
+	source := state.Quirks.LightSource
+	if source < 0 || source >= len(state.ItemLocations) {
+		return true
+	}
+	lamp := state.ItemLocations[source]
+	if lamp == CARRIED {
+		return false
+	}
+	return state.Darkness.LampCarriedOnly || lamp != state.CurrentRoom
+}
+
+// fallInDark applies the darkness rules to a move, reporting whether the
+// player fell to their death
+func fallInDark(state *GameState, blocked bool) bool {
+	rules := state.Darkness
+	if rules.Warning != "" {
+		fmt.Fprintln(state.Output, rules.Warning)
+	}
+	if rules.PitChance <= 0 || (rules.PitBlockedOnly && !blocked) {
+		return false
+	}
+	if state.Rand.Intn(100) >= rules.PitChance {
+		return false
+	}
+
+	fmt.Fprintln(state.Output, personal(state, "I fell into a pit and broke every bone in my body!",
+		"You fell into a pit and broke every bone in your body!"))
+	state.Stats.Deaths++
+	state.BitFlags &= ^uint32(1 << DARKBIT)
+	state.CurrentRoom = state.Header.NumRooms // Last room is typically "death" room
+	state.DisplayedRoom = false
+	state.Outcome = Died
+	return true
+}
+
+// takeItem picks up an item the player asked for, unless the darkness
+// rules hide it or it is already carried
+func takeItem(state *GameState, itemNumber int) {
+	switch {
+	case state.Darkness.Blind && IsDark(state):
+		fmt.Fprintln(state.Output, personal(state, "It is too dark for me to see.", "It is too dark for you to see."))
+	case itemNumber >= 0 && itemNumber < len(state.ItemLocations) && state.ItemLocations[itemNumber] == CARRIED:
+		fmt.Fprintln(state.Output, personal(state, "I'm already carrying that.", "You are already carrying that."))
+	default:
+		GetItem(state, itemNumber)
+		return
+	}
+	failCommand(state)
+}
@@ -0,0 +1,121 @@
+package adventure
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// darkGame compiles a game whose player is in the dark in room a, with an
+// open exit north and a rock on the floor, playing by the given rules
+func darkGame(t *testing.T, rules DarknessRules) (*GameState, *strings.Builder) {
+	t.Helper()
+	state := compileTestGame(t, `
+vocab verb 0 AUT GO "" "" "" "" "" "" "" ""
+vocab verb 10 GET "" "" "" "" "" "" "" DROP
+vocab noun 0 ANY NORTH SOUTH EAST WEST UP DOWN ROCK LAMP
+lightsource lamp
+room a "room A"
+	exit north b
+room b "room B"
+item rock "Rock"
+	called ROCK
+	at a
+item lamp "Lamp"
+	called LAMP
+`)
+	var output strings.Builder
+	state.Output = &output
+	state.Darkness = rules
+	state.BitFlags |= 1 << DARKBIT
+	return state, &output
+}
+
+func TestPitBlockedOnly(t *testing.T) {
+	for _, test := range []struct {
+		direction int
+		died      bool
+	}{
+		{0, false}, // North is open
+		{1, true},  // South is a wall
+	} {
+		state, _ := darkGame(t, DarknessRules{PitChance: 100, PitBlockedOnly: true})
+		MovePlayer(state, test.direction)
+		if died := state.Outcome == Died; died != test.died {
+			t.Errorf("direction %d: died %v, want %v", test.direction, died, test.died)
+		}
+	}
+}
+
+func TestPitChanceIsSeeded(t *testing.T) {
+	deaths := 0
+	for seed := int64(1); seed <= 40; seed++ {
+		state, _ := darkGame(t, StandardDarkness)
+		SetSeed(state, seed)
+		MovePlayer(state, 0)
+
+		want := rand.New(rand.NewSource(seed)).Intn(100) < StandardDarkness.PitChance
+		if died := state.Outcome == Died; died != want {
+			t.Errorf("seed %d: died %v, want %v", seed, died, want)
+		}
+		if state.Outcome == Died {
+			deaths++
+			if state.CurrentRoom != state.Header.NumRooms {
+				t.Errorf("seed %d: dead player in room %d", seed, state.CurrentRoom)
+			}
+		}
+	}
+	if deaths == 0 || deaths == 40 {
+		t.Errorf("%d of 40 seeds were fatal; the roll is not being made", deaths)
+	}
+}
+
+func TestDarknessWarning(t *testing.T) {
+	state, output := darkGame(t, DarknessRules{Warning: "Careful!"})
+	MovePlayer(state, 1)
+	MovePlayer(state, 0)
+	if got := strings.Count(output.String(), "Careful!\n"); got != 2 {
+		t.Errorf("warned %d times for two moves: %q", got, output.String())
+	}
+	if state.CurrentRoom != 2 || state.Outcome != Playing {
+		t.Errorf("player in room %d, %v; want room 2 and still playing", state.CurrentRoom, state.Outcome)
+	}
+}
+
+func TestBlindGet(t *testing.T) {
+	for _, test := range []struct {
+		blind bool
+		place int
+	}{
+		{false, CARRIED},
+		{true, 1},
+	} {
+		state, output := darkGame(t, DarknessRules{Blind: test.blind})
+		if err := ProcessCommand(state, "get rock"); err != nil {
+			t.Fatal(err)
+		}
+		if got := state.ItemLocations[0]; got != test.place {
+			t.Errorf("blind %v: rock at %d, want %d (%q)", test.blind, got, test.place, output.String())
+		}
+	}
+}
+
+func TestLampCarriedOnly(t *testing.T) {
+	for _, test := range []struct {
+		carriedOnly bool
+		lamp        int
+		dark        bool
+	}{
+		{false, CARRIED, false},
+		{false, 1, false},
+		{true, CARRIED, false},
+		{true, 1, true},
+		{false, 2, true},
+	} {
+		state, _ := darkGame(t, DarknessRules{LampCarriedOnly: test.carriedOnly})
+		state.ItemLocations[state.Quirks.LightSource] = test.lamp
+		if dark := IsDark(state); dark != test.dark {
+			t.Errorf("carried only %v, lamp at %d: dark %v, want %v", test.carriedOnly, test.lamp, dark, test.dark)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package adventure
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Decompile writes a game database as ScottKit-style adventure source that
+// Compile turns back into the same game. Rooms and items get names derived
+// from their descriptions, conditions and commands use the symbols from the
+// engine reference, and each action's title follows it as a comment.
+func Decompile(w io.Writer, state *GameState) error {
+	d := &decompiler{state: state, w: bufio.NewWriter(w)}
+	d.nameRooms()
+	d.nameItems()
+	d.countMessages()
+
+	d.header()
+	d.vocabulary()
+	d.rooms()
+	d.items()
+	d.messages()
+	d.actions()
+
+	return d.w.Flush()
+}
+
+// decompiler holds the names chosen for rooms and items while writing
+type decompiler struct {
+	state        *GameState
+	w            *bufio.Writer
+	roomNames    []string
+	itemNames    []string
+	messageCount map[string]int // How often each message text occurs
+}
+
+// Source keywords that cannot be used as names or vocabulary words
+var sourceKeywords = map[string]bool{
+	"when": true, "and": true, "nowhere": true, "carried": true,
+}
+
+// nameRooms gives every room a unique name based on its description
+func (d *decompiler) nameRooms() {
+	used := map[string]bool{}
+	d.roomNames = make([]string, len(d.state.Rooms))
+	for i, room := range d.state.Rooms {
+		d.roomNames[i] = uniqueName(sourceName(room.Description), "room", i, used)
+	}
+}
+
+// nameItems gives every item a unique name based on its description
+func (d *decompiler) nameItems() {
+	used := map[string]bool{}
+	d.itemNames = make([]string, len(d.state.Items))
+	for i, item := range d.state.Items {
+		d.itemNames[i] = uniqueName(sourceName(item.Description), "item", i, used)
+	}
+}
+
+// countMessages finds message texts that occur more than once, which have
+// to be referenced by number rather than by text
+func (d *decompiler) countMessages() {
+	d.messageCount = map[string]int{}
+	for _, msg := range d.state.Messages {
+		d.messageCount[msg]++
+	}
+}
+
+// sourceName turns a description into an identifier such as "brass_lamp"
+func sourceName(desc string) string {
+	var words []string
+	desc = strings.ReplaceAll(strings.ToLower(desc), "'", "")
+	for _, field := range strings.FieldsFunc(desc, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words = append(words, field)
+		if len(words) == 3 {
+			break
+		}
+	}
+	return strings.Join(words, "_")
+}
+
+// uniqueName makes a name unique, falling back to prefix+index when the
+// description gives nothing usable
+func uniqueName(name string, prefix string, index int, used map[string]bool) string {
+	if name == "" || sourceKeywords[name] || unicode.IsDigit(rune(name[0])) {
+		name = fmt.Sprintf("%s%d", prefix, index)
+	}
+	base := name
+	for n := 2; used[name]; n++ {
+		name = fmt.Sprintf("%s_%d", base, n)
+	}
+	used[name] = true
+	return name
+}
+
+// quoteText quotes a string for source, escaping quotes, backslashes and
+// line breaks so each statement stays on one line
+func quoteText(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// printf writes a formatted line of source
+func (d *decompiler) printf(format string, args ...interface{}) {
+	fmt.Fprintf(d.w, format, args...)
+}
+
+// header writes the game settings
+func (d *decompiler) header() {
+	h := d.state.Header
+	d.printf("# Adventure %d, version %d.%02d\n", h.AdventureNumber, h.AdventureVersion/100, h.AdventureVersion%100)
+	d.printf("ident %d\n", h.AdventureNumber)
+	d.printf("version %d\n", h.AdventureVersion)
+	d.printf("textsize %d\n", h.TextStorageBytes)
+	d.printf("wordlen %d\n", h.WordLength)
+	d.printf("maxload %d\n", h.MaxCarry)
+	d.printf("lighttime %d\n", h.LightTime)
+	d.printf("treasures %d\n", h.Treasures)
+	d.printf("start %s\n", d.room(h.PlayerRoom))
+	d.printf("treasury %s\n", d.room(h.TreasureRoom))
+}
+
+// Vocabulary words written on each vocab line
+const vocabLineWords = 10
+
+// vocabulary writes every slot of both tables, ten to a line with the
+// index of the first. Empty slots are written as "" so the compiler keeps
+// the tables exactly as they are.
+func (d *decompiler) vocabulary() {
+	for _, table := range []struct {
+		name  string
+		words []Word
+	}{{"verb", d.state.Verbs}, {"noun", d.state.Nouns}} {
+		d.printf("\n")
+		for start := 0; start < len(table.words); start += vocabLineWords {
+			var run []string
+			for _, w := range table.words[start:min(start+vocabLineWords, len(table.words))] {
+				word := encodeWord(w)
+				if !isSourceWord(strings.TrimPrefix(word, "*")) {
+					word = quoteText(word)
+				}
+				run = append(run, word)
+			}
+			d.printf("vocab %s %d %s\n", table.name, start, strings.Join(run, " "))
+		}
+	}
+}
+
+// rooms writes every room with its exits. Room 0 is written as room
+// nowhere, and only when it has a description or exits.
+func (d *decompiler) rooms() {
+	directions := []string{"north", "south", "east", "west", "up", "down"}
+	for i, room := range d.state.Rooms {
+		name := d.roomNames[i]
+		if i == 0 {
+			if room.Description == "" && room.Exits == [6]int{} {
+				continue
+			}
+			name = "nowhere"
+		}
+		d.printf("\nroom %s %s\n", name, quoteText(room.Description))
+		for dir, exit := range room.Exits {
+			if exit != 0 {
+				d.printf("\texit %s %s\n", directions[dir], d.room(exit))
+			}
+		}
+	}
+}
+
+// items writes every item in number order, so item 9 stays the light
+func (d *decompiler) items() {
+	for i, item := range d.state.Items {
+		d.printf("\nitem %s %s\n", d.itemNames[i], quoteText(item.Description))
+		if item.AutoGet != "" {
+			d.printf("\tcalled %s\n", item.AutoGet)
+		}
+		if item.OriginalLocation != DESTROYED {
+			d.printf("\tat %s\n", d.room(item.OriginalLocation))
+		}
+	}
+}
+
+// messages writes the message table, with message 0 only when it has text
+func (d *decompiler) messages() {
+	d.printf("\n")
+	if len(d.state.Messages) > 0 && d.state.Messages[0] != "" {
+		d.printf("message 0 %s\n", quoteText(d.state.Messages[0]))
+	}
+	for i := 1; i < len(d.state.Messages); i++ {
+		d.printf("message %s  # %d\n", quoteText(d.state.Messages[i]), i)
+	}
+}
+
+// actions writes every action with its conditions and commands
+func (d *decompiler) actions() {
+	for i, action := range d.state.Actions {
+		var line string
+		switch {
+		case action.Verb == 0 && action.Noun == 0:
+			line = "continuation"
+		case action.Verb == 0:
+			line = fmt.Sprintf("occur %d%%", action.Noun)
+		default:
+			line = "action " + d.word(d.state.Verbs, action.Verb)
+			if action.Noun != 0 {
+				line += " " + d.word(d.state.Nouns, action.Noun)
+			}
+		}
+
+		// Conditions, leaving out the PARs that only pass parameters
+		var conditions []string
+		for _, cond := range action.Conditions {
+			code, parameter := DecodeCondition(cond)
+			switch {
+			case code == CondPAR:
+			case code < 0: // A negative value has no condition code; write it as it is
+				conditions = append(conditions, strconv.Itoa(cond))
+			default:
+				conditions = append(conditions, d.operation(Conditions[code], []int{parameter}))
+			}
+		}
+		if len(conditions) > 0 {
+			line += " when " + strings.Join(conditions, " and ")
+		}
+
+		if i < len(d.state.ActionTitles) && d.state.ActionTitles[i] != "" {
+			line += "  # " + strings.ReplaceAll(d.state.ActionTitles[i], "\n", " ")
+		}
+		d.printf("\n%s\n", line)
+
+		// Commands, with parameters taken in order from the PAR conditions
+		params := ActionParameters(action)
+		for _, cmd := range ActionCommands(action) {
+			switch opcode, known := Commands[cmd]; {
+			case cmd == 0:
+				continue
+			case IsMessageCommand(cmd):
+				d.printf("\t%s %s\n", MessageOpcode.Name, d.message(MessageNumber(cmd)))
+			case known:
+				var args []int
+				for range opcode.Args {
+					parameter := 0
+					if len(params) > 0 {
+						parameter, params = params[0], params[1:]
+					}
+					args = append(args, parameter)
+				}
+				// A duplicate code is kept by number so it compiles back unchanged
+				if primary, _ := CommandCode(opcode.Name); primary != cmd {
+					d.printf("\t%s  # %s\n", d.operation(Opcode{strconv.Itoa(cmd), opcode.Args}, args), opcode.Name)
+					continue
+				}
+				d.printf("\t%s\n", d.operation(opcode, args))
+			default:
+				d.printf("\t%d  # undefined command\n", cmd)
+			}
+		}
+	}
+}
+
+// operation renders a condition or command with its resolved arguments
+func (d *decompiler) operation(op Opcode, args []int) string {
+	parts := []string{op.Name}
+	for n, kind := range op.Args {
+		parts = append(parts, d.argument(kind, args[n]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// argument renders a parameter as a room, item or message reference
+func (d *decompiler) argument(kind ArgKind, value int) string {
+	switch kind {
+	case ArgItem:
+		if value >= 0 && value < len(d.itemNames) {
+			return d.itemNames[value]
+		}
+	case ArgRoom, ArgPlace:
+		return d.room(value)
+	case ArgMessage:
+		return d.message(value)
+	}
+	return strconv.Itoa(value)
+}
+
+// room renders a room reference
+func (d *decompiler) room(value int) string {
+	switch {
+	case value == DESTROYED:
+		return "nowhere"
+	case value == CARRIED:
+		return "carried"
+	case value > 0 && value < len(d.roomNames):
+		return d.roomNames[value]
+	}
+	return strconv.Itoa(value)
+}
+
+// message renders a message by its text, or by number when the text is
+// not unique or the message does not exist
+func (d *decompiler) message(value int) string {
+	if value > 0 && value < len(d.state.Messages) && d.messageCount[d.state.Messages[value]] == 1 {
+		return quoteText(d.state.Messages[value])
+	}
+	return strconv.Itoa(value)
+}
+
+// word renders a vocabulary reference by its word, or by number when the
+// word is empty, is not the first word with that text, or could not be read
+// back as a word
+func (d *decompiler) word(table []Word, index int) string {
+	if index <= 0 || index >= len(table) {
+		return strconv.Itoa(index)
+	}
+	w := table[index].Word
+	if w == "" || table[index].IsSynonym || sourceKeywords[strings.ToLower(w)] || !isSourceWord(w) {
+		return strconv.Itoa(index)
+	}
+	for i := 1; i < index; i++ {
+		if strings.EqualFold(table[i].Word, w) {
+			return strconv.Itoa(index)
+		}
+	}
+	return w
+}
+
+// isSourceWord reports whether a vocabulary word can appear unquoted
+func isSourceWord(w string) bool {
+	if w == "" {
+		return false
+	}
+	if _, err := strconv.Atoi(w); err == nil {
+		return false
+	}
+	for _, r := range w {
+		if unicode.IsSpace(r) || r == '"' || r == '#' || r == '*' {
+			return false
+		}
+	}
+	return true
+}
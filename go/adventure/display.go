@@ -0,0 +1,182 @@
+package adventure
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DumpVocabulary prints all vocabulary words (helpful for debugging)
+func DumpVocabulary(state *GameState) {
+	fmt.Fprintln(state.Output, "\n--- Vocabulary Dump ---")
+	fmt.Fprintln(state.Output, "Index | Verb       | Noun")
+	fmt.Fprintln(state.Output, "------|------------|------------")
+
+	for i := range state.Verbs {
+		fmt.Fprintf(state.Output, "%5d | %-10s | %s\n", i,
+			formatWord(state.Verbs[i]), formatWord(state.Nouns[i]))
+	}
+
+	fmt.Fprint(state.Output, "----------------------\n\n")
+}
+
+// formatWord shows a vocabulary entry with its synonym mark and target
+func formatWord(word Word) string {
+	if word.IsSynonym {
+		return fmt.Sprintf("*%s=%d", word.Word, word.Index)
+	}
+	return " " + word.Word
+}
+
+// DisplayInventory shows the items the player is carrying
+func DisplayInventory(state *GameState) {
+	fmt.Fprintln(state.Output, personal(state, "I'm carrying:", "You are carrying:"))
+
+	count := 0
+	for i, loc := range state.ItemLocations {
+		if i <= state.Header.NumItems && loc == CARRIED {
+			count++
+			fmt.Fprintf(state.Output, "- %s\n", getItemDescription(state, i))
+		}
+	}
+
+	if count == 0 {
+		fmt.Fprintln(state.Output, "Nothing.")
+	}
+}
+
+// DisplayHelp shows help information, naming the directions and the GET
+// and DROP verbs with the game's own words
+func DisplayHelp(state *GameState) {
+	fmt.Fprintln(state.Output, "Commands you can use:")
+	var directions []string
+	for dir, letter := range directionLetters(state) {
+		if dir+1 >= len(state.Nouns) || state.Nouns[dir+1].Word == "" {
+			continue
+		}
+		direction := state.Nouns[dir+1].Word
+		if letter != "" {
+			direction += " (" + letter + ")"
+		}
+		directions = append(directions, direction)
+	}
+	if len(directions) > 0 {
+		fmt.Fprintf(state.Output, "- Direction commands: %s\n", strings.Join(directions, ", "))
+	}
+	fmt.Fprintf(state.Output, "- %s [item]: Pick up an item\n", verbWords(state, state.Quirks.GetVerb, "GET"))
+	fmt.Fprintf(state.Output, "- %s [item]: Drop an item you're carrying\n", verbWords(state, state.Quirks.DropVerb, "DROP"))
+	fmt.Fprintln(state.Output, "- INVENTORY/I: See what you're carrying")
+	fmt.Fprintln(state.Output, "- LOOK: Look around again")
+	fmt.Fprintln(state.Output, "- SCORE: See your current score")
+	fmt.Fprintln(state.Output, "- SAVE/LOAD: Save or load your game")
+	fmt.Fprintln(state.Output, "- QUIT: End the game")
+}
+
+// verbWords joins the words for a verb and its synonyms with slashes, or
+// returns fallback if the game has no word for it
+func verbWords(state *GameState, verb int, fallback string) string {
+	var words []string
+	for _, word := range state.Verbs {
+		if word.Index == verb && word.Word != "" {
+			words = append(words, word.Word)
+		}
+	}
+	if len(words) == 0 {
+		return fallback
+	}
+	return strings.Join(words, "/")
+}
+
+// DisplayCurrentLocation shows the current room and its contents
+func DisplayCurrentLocation(state *GameState) {
+	// Check if room is dark
+	if IsDark(state) {
+		if state.Options.TRS80Style {
+			fmt.Fprintln(state.Output, personal(state, "I can't see. It is too dark!", "You can't see. It is too dark!"))
+		} else {
+			fmt.Fprintln(state.Output, "It is too dark to see")
+		}
+		return
+	}
+
+	if !roomExists(state, state.CurrentRoom) {
+		fmt.Fprintf(state.Output, "Engine error: the player is in room %d, which does not exist\n", state.CurrentRoom)
+		return
+	}
+	room := state.Rooms[state.CurrentRoom]
+
+	// Room description
+	var desc string
+	if strings.HasPrefix(room.Description, "*") {
+		// Direct description (without "I'm in a" prefix)
+		desc = strings.TrimPrefix(room.Description, "*")
+	} else {
+		// Prefixed description
+		desc = personal(state, "I'm in a ", "You are in a ") + room.Description
+	}
+
+	// Visible items
+	var items []string
+	for i, loc := range state.ItemLocations {
+		if i <= state.Header.NumItems && loc == state.CurrentRoom {
+			desc := state.Items[i].Description
+			// Remove AutoGet part if present
+			if idx := strings.Index(desc, "/"); idx != -1 {
+				desc = desc[:idx]
+			}
+			items = append(items, desc)
+		}
+	}
+
+	// Available exits, named with the game's direction words
+	var exits []string
+	for dir := range room.Exits {
+		if room.Exits[dir] != 0 {
+			exits = append(exits, directionName(state, dir))
+		}
+	}
+
+	if state.Options.TRS80Style {
+		displayTRS80Location(state, desc, items, exits)
+		return
+	}
+
+	fmt.Fprintln(state.Output, desc)
+	for _, item := range items {
+		fmt.Fprintf(state.Output, personal(state, "I can see %s here\n", "You can see %s here\n"), item)
+	}
+	if len(exits) > 0 {
+		fmt.Fprintf(state.Output, "Obvious exits: %s\n", strings.Join(exits, ", "))
+	} else {
+		fmt.Fprintln(state.Output, "Obvious exits: NONE")
+	}
+}
+
+// directionName returns the game's word for a direction, noun dir+1, or
+// the English name if the game has no word for it
+func directionName(state *GameState, dir int) string {
+	if dir+1 < len(state.Nouns) && state.Nouns[dir+1].Word != "" {
+		return state.Nouns[dir+1].Word
+	}
+	return []string{"NORTH", "SOUTH", "EAST", "WEST", "UP", "DOWN"}[dir]
+}
+
+// displayTRS80Location lays out a room the way the TRS-80 original did:
+// exits before items, items run together, all wrapped to 64 columns
+func displayTRS80Location(state *GameState, desc string, items []string, exits []string) {
+	fmt.Fprintln(state.Output, wrapText(desc, trs80Width))
+
+	exitList := "none"
+	if len(exits) > 0 {
+		for i, exit := range exits {
+			exits[i] = exit[:1] + strings.ToLower(exit[1:])
+		}
+		exitList = strings.Join(exits, ", ")
+	}
+	fmt.Fprintf(state.Output, "Obvious exits: %s.\n", exitList)
+
+	if len(items) > 0 {
+		seen := personal(state, "I can also see: ", "You can also see: ") + strings.Join(items, " - ")
+		fmt.Fprintf(state.Output, "\n%s\n", wrapText(seen, trs80Width))
+	}
+	fmt.Fprintln(state.Output, trs80Line)
+}
@@ -0,0 +1,47 @@
+package adventure
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHelpUsesGameWords(t *testing.T) {
+	state := compileTestGame(t, `
+vocab verb 0 AUT GEH "" "" "" "" "" "" "" ""
+vocab verb 10 NIMM *HOL "" "" "" "" "" "" LEG
+vocab noun 0 ANY NORD SUED OST WEST OBEN UNTEN
+room a "Raum A"
+`)
+	var output strings.Builder
+	state.Output = &output
+	DisplayHelp(state)
+
+	for _, want := range []string{
+		"- Direction commands: NORD (N), SUED (S), OST, WEST (W), OBEN, UNTEN (U)\n",
+		"- NIMM/HOL [item]: Pick up an item\n",
+		"- LEG [item]: Drop an item",
+	} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("help does not contain %q:\n%s", want, output.String())
+		}
+	}
+}
+
+func TestExitsUseGameWords(t *testing.T) {
+	state := compileTestGame(t, `
+vocab noun 0 ANY NORD SUED OST "" OBEN UNTEN
+room a "Raum A"
+	exit north b
+	exit east b
+	exit up b
+room b "Raum B"
+`)
+	var output strings.Builder
+	state.Output = &output
+	state.Rooms[1].Exits[3] = 2 // West, which the game has no word for
+	DisplayCurrentLocation(state)
+
+	if want := "Obvious exits: NORD, OST, WEST, OBEN\n"; !strings.Contains(output.String(), want) {
+		t.Errorf("got %q, want it to contain %q", output.String(), want)
+	}
+}
@@ -0,0 +1,740 @@
+package adventure
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// RunGame implements the main game loop, reading commands from in and
+// writing all output to out until the player quits or input runs out
+func RunGame(state *GameState, in io.Reader, out io.Writer) {
+	SetIO(state, in, out)
+
+	StartGame(state)
+	for PlayTurn(state) {
+	}
+}
+
+// StartGame runs the automatic actions once before the first turn, so
+// opening events happen before the player is first prompted
+func StartGame(state *GameState) {
+	if err := ProcessAutomaticActions(state); err != nil {
+		reportEngineError(state, err)
+	}
+}
+
+// PlayTurn runs a single turn of the game using the state's input and
+// output: show the room if needed, read a command, count the turn, perform
+// the command, update the light source, then run the automatic actions. An
+// empty line is not a turn: nothing runs, the light does not burn and the
+// player is prompted again. Once the game is over the turn instead offers
+// to restart, restore or quit. It returns false once the player has quit
+// or input is exhausted. Call StartGame before the first turn.
+func PlayTurn(state *GameState) bool {
+	if state.Outcome != Playing {
+		return GameOver(state)
+	}
+
+	visitRoom(state)
+
+	// Display current location if not already displayed this turn
+	if !state.DisplayedRoom {
+		DisplayCurrentLocation(state)
+		state.DisplayedRoom = true
+	}
+
+	// Get player input
+	fmt.Fprint(state.Output, "> ")
+	input, err := readLine(state)
+	if err != nil {
+		return false
+	}
+
+	// An empty line is not a turn
+	if input == "" {
+		return true
+	}
+
+	// Reset room display flag for next turn
+	state.DisplayedRoom = false
+	state.Stats.Turns++
+
+	// Handle quit command, summing up the game as GameOver does
+	if strings.ToUpper(input) == "QUIT" {
+		state.Outcome = Quit
+		fmt.Fprintf(state.Output, "The game is now over: you %s.\n", state.Outcome)
+		DisplayStatistics(state)
+		fmt.Fprintln(state.Output, "Thanks for playing!")
+		return false
+	}
+
+	// Process player command; an engine error abandons the rest of the turn
+	if err := ProcessCommand(state, input); err != nil {
+		failCommand(state)
+		reportEngineError(state, err)
+		return true
+	}
+	if state.Outcome != Playing {
+		return true
+	}
+
+	// Update light source status
+	UpdateLightSource(state)
+
+	// Process automatic actions
+	if err := ProcessAutomaticActions(state); err != nil {
+		reportEngineError(state, err)
+	}
+
+	return true
+}
+
+// GameOver reports how the game ended and asks whether to restart, restore
+// a saved game or quit. It returns false if the player quits or input is
+// exhausted, and true once a new or restored game is ready to play.
+func GameOver(state *GameState) bool {
+	fmt.Fprintf(state.Output, "The game is now over: you %s.\n", state.Outcome)
+	DisplayStatistics(state)
+
+	for {
+		fmt.Fprint(state.Output, "Would you like to RESTART, RESTORE a saved game or QUIT? ")
+		input, err := readLine(state)
+		if err != nil {
+			return false
+		}
+
+		switch strings.ToUpper(input) {
+		case "RESTART", "R":
+			ResetGame(state)
+			StartGame(state)
+			return true
+		case "RESTORE":
+			if LoadGame(state) {
+				state.Outcome = Playing
+				return true
+			}
+		case "QUIT", "Q":
+			fmt.Fprintln(state.Output, "Thanks for playing!")
+			return false
+		}
+	}
+}
+
+// ProcessAutomaticActions makes one pass over the actions with verb=0.
+// Entries with a noun of 1-100 run with that percentage chance, rolled once
+// per pass; entries with noun 0 are continuations and only run as part of
+// a CONT chain. The pass stops at the first engine error.
+func ProcessAutomaticActions(state *GameState) error {
+	for i := 0; i < len(state.Actions); i++ {
+		action := state.Actions[i]
+
+		// Process actions with verb=0 (automatic actions)
+		if action.Verb != 0 || action.Noun == 0 {
+			continue
+		}
+
+		// The noun is the percentage chance of the action happening
+		chance := state.Rand.Intn(100) + 1
+		if chance > action.Noun {
+			continue
+		}
+
+		// Check conditions
+		ok, err := CheckConditions(state, i)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if err := ExecuteCommands(state, i); err != nil {
+				return err
+			}
+			if state.ContinueFlag {
+				if i, err = RunContinuation(state, i); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Longest continuation chain run after a single CONT
+const maxContinuationChain = 256
+
+// RunContinuation runs the continuation entries (verb 0, noun 0) following
+// the action at actionIndex, which executed CONT. Entries whose conditions
+// fail are skipped, and the chain ends at the first entry with a non-zero
+// vocabulary value. It returns the index of the last entry in the chain.
+func RunContinuation(state *GameState, actionIndex int) (int, error) {
+	state.ContinueFlag = false
+
+	i := actionIndex + 1
+	for ; i < len(state.Actions); i++ {
+		action := state.Actions[i]
+		if action.Verb != 0 || action.Noun != 0 {
+			break
+		}
+
+		// Guard against a runaway chain in a damaged database
+		if i-actionIndex > maxContinuationChain {
+			if state.Debug {
+				fmt.Fprintf(state.Output, "[DEBUG] Continuation chain from action %d stopped after %d entries\n",
+					actionIndex, maxContinuationChain)
+			}
+			break
+		}
+
+		ok, err := CheckConditions(state, i)
+		if err == nil && ok {
+			err = ExecuteCommands(state, i)
+		}
+		if err != nil {
+			state.ContinueFlag = false
+			return i, err
+		}
+	}
+
+	// A CONT inside the chain has nothing further to continue
+	state.ContinueFlag = false
+	return i - 1, nil
+}
+
+// ProcessActionsWithVerb checks actions matching player's verb/noun
+func ProcessActionsWithVerb(state *GameState, verb int, noun int) error {
+	// First try exact verb+noun match
+	if found, err := ProcessExactAction(state, verb, noun); found || err != nil {
+		return err
+	}
+
+	// Try verb with ANY noun (noun=0)
+	if noun != 0 {
+		if found, err := ProcessExactAction(state, verb, 0); found || err != nil {
+			return err
+		}
+	}
+
+	// Handle built-in commands if no matching action, finding the item
+	// from the noun as typed
+	if verb == state.Quirks.GetVerb { // CARRY/GET
+		if item := findItem(state, state.NounText, state.CurrentRoom, CARRIED); item >= 0 {
+			takeItem(state, item)
+		} else {
+			notAnItem(state)
+		}
+		return nil
+	}
+
+	if verb == state.Quirks.DropVerb { // DROP
+		if item := findItem(state, state.NounText, CARRIED, state.CurrentRoom); item >= 0 {
+			DropItem(state, item)
+		} else {
+			notAnItem(state)
+		}
+		return nil
+	}
+
+	// No matching action found
+	failCommand(state)
+	fmt.Fprintln(state.Output, personal(state, "I don't understand how to do that.", "You don't understand how to do that."))
+	return nil
+}
+
+// ProcessExactAction checks and executes the first action with an exact
+// verb/noun match whose conditions pass, followed by its continuation chain
+// if it executed CONT
+func ProcessExactAction(state *GameState, verb int, noun int) (bool, error) {
+	for i, action := range state.Actions {
+		if action.Verb != verb || action.Noun != noun {
+			continue
+		}
+
+		ok, err := CheckConditions(state, i)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			continue
+		}
+
+		if err := ExecuteCommands(state, i); err != nil {
+			return true, err
+		}
+		if state.ContinueFlag {
+			if _, err := RunContinuation(state, i); err != nil {
+				return true, err
+			}
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// CheckConditions verifies if all conditions for an action are met
+func CheckConditions(state *GameState, actionIndex int) (bool, error) {
+	// Store current action index so errors can name it
+	state.CurrentAction = actionIndex
+	action := state.Actions[actionIndex]
+
+	// Each condition must be true for action to proceed
+	for _, encodedCondition := range action.Conditions {
+		if encodedCondition == 0 {
+			continue // Condition code 0 (PAR) always returns true
+		}
+
+		conditionCode := encodedCondition % 20
+		parameter := encodedCondition / 20
+
+		ok, err := EvaluateCondition(state, conditionCode, parameter)
+		if !ok || err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// EvaluateCondition checks a single condition, returning an EngineError if
+// its parameter is out of range
+func EvaluateCondition(state *GameState, code int, parameter int) (bool, error) {
+	if code < 0 || code >= len(Conditions) {
+		return false, newEngineError(state, "condition", code, "unknown condition code")
+	}
+
+	op := state.Extensions.Condition(code)
+	for _, kind := range op.Args {
+		if err := checkArgument(state, op.Name, kind, parameter); err != nil {
+			return false, err
+		}
+	}
+
+	// Registered conditions take the place of the built-in ones
+	if ext, ok := state.Extensions.conditions[code]; ok {
+		return ext.fn(state, parameter), nil
+	}
+
+	return evaluateCondition(state, code, parameter), nil
+}
+
+// evaluateCondition checks a built-in condition whose parameter is valid
+func evaluateCondition(state *GameState, code int, parameter int) bool {
+	switch code {
+	case 0: // PAR - Always true (parameter is passed to action)
+		return true
+	case 1: // HAS - Player is carrying item [parameter]
+		return state.ItemLocations[parameter] == CARRIED
+	case 2: // IN/W - Item [parameter] is in current room
+		return state.ItemLocations[parameter] == state.CurrentRoom
+	case 3: // AVL - Item [parameter] is carried or in current room
+		return state.ItemLocations[parameter] == CARRIED || state.ItemLocations[parameter] == state.CurrentRoom
+	case 4: // IN - Player is in room [parameter]
+		return state.CurrentRoom == parameter
+	case 5: // -IN/W - Item [parameter] is not in current room
+		return state.ItemLocations[parameter] != state.CurrentRoom
+	case 6: // -HAVE - Player is not carrying item [parameter]
+		return state.ItemLocations[parameter] != CARRIED
+	case 7: // -IN - Player is not in room [parameter]
+		return state.CurrentRoom != parameter
+	case 8: // BIT - Bit flag [parameter] is set
+		return state.BitFlags&(1<<uint(parameter)) != 0
+	case 9: // -BIT - Bit flag [parameter] is not set
+		return state.BitFlags&(1<<uint(parameter)) == 0
+	case 10: // ANY - Player is carrying at least one item
+		for i, loc := range state.ItemLocations {
+			if i <= state.Header.NumItems && loc == CARRIED {
+				return true
+			}
+		}
+		return false
+	case 11: // -ANY - Player is not carrying any items
+		for i, loc := range state.ItemLocations {
+			if i <= state.Header.NumItems && loc == CARRIED {
+				return false
+			}
+		}
+		return true
+	case 12: // -AVL - Item [parameter] is not carried or in current room
+		return state.ItemLocations[parameter] != CARRIED && state.ItemLocations[parameter] != state.CurrentRoom
+	case 13: // -RM0 - Item [parameter] is not in room 0 (not destroyed)
+		return state.ItemLocations[parameter] != DESTROYED
+	case 14: // RM0 - Item [parameter] is in room 0 (destroyed)
+		return state.ItemLocations[parameter] == DESTROYED
+	case 15: // CT<= - Counter <= [parameter]
+		return state.Counter <= parameter
+	case 16: // CT> - Counter > [parameter]
+		return state.Counter > parameter
+	case 17: // ORIG - Item [parameter] is in its original location
+		return state.ItemLocations[parameter] == state.Items[parameter].OriginalLocation
+	case 18: // -ORIG - Item [parameter] is not in its original location
+		return state.ItemLocations[parameter] != state.Items[parameter].OriginalLocation
+	case 19: // CT= - Counter = [parameter]
+		return state.Counter == parameter
+	default:
+		return false
+	}
+}
+
+// ExecuteCommands processes the commands for an action, stopping at the
+// first engine error
+func ExecuteCommands(state *GameState, actionIndex int) error {
+	// Store current action index for condition parameter access
+	state.CurrentAction = actionIndex
+	action := state.Actions[actionIndex]
+
+	// Commands take their parameters in order from the PAR conditions
+	params := ActionParameters(action)
+
+	// Actions have two command "pairs", giving up to four commands
+	for _, cmd := range ActionCommands(action) {
+		if cmd == 0 {
+			continue // No command
+		}
+
+		var err error
+		if params, err = ExecuteCommand(state, cmd, params); err != nil {
+			return err
+		}
+	}
+
+	// Debug output
+	if state.Debug {
+		title := "Unknown"
+		if actionIndex < len(state.ActionTitles) {
+			title = state.ActionTitles[actionIndex]
+		}
+		fmt.Fprintf(state.Output, "[DEBUG] Executed action %d: %s\n", actionIndex, title)
+	}
+
+	return nil
+}
+
+// ExecuteCommand processes a single command. Commands that need parameters
+// take them from the front of params; the unused parameters are returned.
+// Parameters are checked before the command runs, and one that is out of
+// range gives an EngineError instead.
+func ExecuteCommand(state *GameState, cmd int, params []int) ([]int, error) {
+	// Take the next parameter passed by a PAR condition
+	next := func() int {
+		if len(params) == 0 {
+			return 0
+		}
+		parameter := params[0]
+		params = params[1:]
+		return parameter
+	}
+
+	// Check the parameters the command will take
+	if op, ok := state.Extensions.Command(cmd); ok {
+		for n, kind := range op.Args {
+			value := 0
+			if n < len(params) {
+				value = params[n]
+			}
+			if err := checkArgument(state, op.Name, kind, value); err != nil {
+				return params, err
+			}
+		}
+	}
+
+	// Registered commands get all of their parameters at once
+	if ext, ok := state.Extensions.commands[cmd]; ok {
+		args := make([]int, len(ext.op.Args))
+		for i := range args {
+			args[i] = next()
+		}
+		if err := ext.fn(state, args); err != nil {
+			var engineErr *EngineError
+			if !errors.As(err, &engineErr) {
+				err = newEngineError(state, ext.op.Name, cmd, err.Error())
+			}
+			return params, err
+		}
+		return params, nil
+	}
+
+	// Only commands that declare parameters consume one
+	parameter := 0
+	if op, ok := Commands[cmd]; ok && len(op.Args) > 0 {
+		parameter = next()
+	}
+
+	// Command is a message to display (1-51, or 52-99 encoded as 102-149)
+	if IsMessageCommand(cmd) {
+		msg := MessageNumber(cmd)
+		if err := checkArgument(state, MessageOpcode.Name, ArgMessage, msg); err != nil {
+			return params, err
+		}
+		fmt.Fprintln(state.Output, state.Messages[msg])
+		return params, nil
+	}
+
+	// Action commands (52-101)
+	switch cmd {
+	case 52: // GETx - Pick up item x (fail if carrying too many items)
+		GetItem(state, parameter)
+	case 53: // DROPx - Drop item x in current room
+		DropItem(state, parameter)
+	case 54: // GOTOy - Move player to room y
+		state.CurrentRoom = parameter
+		state.DisplayedRoom = false
+	case 55, 59: // x->RM0 - Move item x to room 0 (destroy it)
+		state.ItemLocations[parameter] = DESTROYED
+	case 56: // NIGHT - Set darkness bit (15)
+		state.BitFlags |= (1 << DARKBIT)
+	case 57: // DAY - Clear darkness bit (15)
+		state.BitFlags &= ^uint32(1 << DARKBIT)
+	case 58: // SETz - Set bit flag z
+		state.BitFlags |= (1 << uint(parameter))
+	case 60: // CLRz - Clear bit flag z
+		state.BitFlags &= ^(1 << uint(parameter))
+	case 61: // DEAD - Kill player (move to last room, show death message)
+		fmt.Fprintln(state.Output, personal(state, "I am dead.", "You are dead."))
+		state.Stats.Deaths++
+		state.BitFlags &= ^uint32(1 << DARKBIT)
+		state.CurrentRoom = state.Header.NumRooms
+		state.DisplayedRoom = false
+		state.Outcome = Died
+	case 62: // x->y - Move item x to room y
+		state.ItemLocations[parameter] = next()
+	case 63: // FINI - End game, won only if the score is complete
+		switch {
+		case state.Outcome != Playing:
+		case CurrentScore(state).Complete():
+			state.Outcome = Won
+		default:
+			state.Outcome = Quit
+		}
+	case 64, 76: // DspRM - Show room description
+		state.DisplayedRoom = false
+	case 65: // SCORE - Show score
+		DisplayScore(state)
+	case 66: // INV - Show inventory
+		DisplayInventory(state)
+	case 67: // SET0 - Set bit flag 0
+		state.BitFlags |= (1 << 0)
+	case 68: // CLR0 - Clear bit flag 0
+		state.BitFlags &= ^uint32(1 << 0)
+	case 69: // FILL - Refill light source
+		lamp := state.Quirks.LightSource
+		if lamp < 0 || lamp >= len(state.ItemLocations) {
+			return params, newEngineError(state, Commands[cmd].Name, lamp, "game has no light source")
+		}
+		state.AltCounters[8] = state.Header.LightTime
+		state.BitFlags &= ^uint32(1 << LIGHTOUTBIT)
+		// Move light source to inventory if not already there
+		if state.ItemLocations[lamp] != CARRIED {
+			state.ItemLocations[lamp] = CARRIED
+		}
+	case 70: // CLS - Clear screen
+		fmt.Fprint(state.Output, "\033[H\033[2J") // ANSI escape sequence to clear screen
+	case 71: // SAVE - Save game
+		SaveGame(state)
+	case 72: // EXx,x - Swap locations of two items
+		item1 := parameter
+		item2 := next()
+
+		// Swap locations
+		state.ItemLocations[item1], state.ItemLocations[item2] = state.ItemLocations[item2], state.ItemLocations[item1]
+	case 73: // CONT - Run the continuation entries that follow this action
+		state.ContinueFlag = true
+	case 74: // AGETx - Pick up item x (no carrying capacity check)
+		state.ItemLocations[parameter] = CARRIED
+	case 75: // BYx<-x - Item x gets location of item y
+		item1 := parameter
+		item2 := next()
+
+		state.ItemLocations[item1] = state.ItemLocations[item2]
+	case 77: // CT-1 - Decrement counter
+		state.Counter--
+	case 78: // DspCT - Display counter value
+		fmt.Fprintf(state.Output, "Counter = %d\n", state.Counter)
+	case 79: // CT<-n - Set counter to n
+		state.Counter = parameter
+	case 80: // EXRM0 - Swap current room with alternate room 0
+		state.CurrentRoom, state.AltRooms[0] = state.AltRooms[0], state.CurrentRoom
+		state.DisplayedRoom = false
+	case 81: // EXm,CT - Swap counter with alternate counter m
+		state.Counter, state.AltCounters[parameter] = state.AltCounters[parameter], state.Counter
+	case 82: // CT+n - Add n to counter
+		state.Counter += parameter
+	case 83: // CT-n - Subtract n from counter (minimum -1)
+		state.Counter -= parameter
+		if state.Counter < -1 {
+			state.Counter = -1
+		}
+	case 84: // SAYw - Display noun entered by player
+		fmt.Fprint(state.Output, state.NounText)
+	case 85: // SAYwCR - Display noun entered by player with newline
+		fmt.Fprintln(state.Output, state.NounText)
+	case 86: // SAYCR - Display newline
+		fmt.Fprintln(state.Output)
+	case 87: // EXc,CR - Swap current room with alternate room c
+		state.CurrentRoom, state.AltRooms[parameter] = state.AltRooms[parameter], state.CurrentRoom
+		state.DisplayedRoom = false
+	case 88: // DELAY - Pause for a moment
+		time.Sleep(500 * time.Millisecond)
+	default: // 89-101 are undefined unless registered in state.Extensions
+		if state.Debug {
+			fmt.Fprintf(state.Output, "[DEBUG] Undefined command code %d in action %d\n", cmd, state.CurrentAction)
+		}
+	}
+
+	return params, nil
+}
+
+// GetItem attempts to pick up an item
+func GetItem(state *GameState, itemNumber int) {
+	// Check if item exists
+	if itemNumber < 0 || itemNumber > state.Header.NumItems {
+		fmt.Fprintln(state.Output, personal(state, "I don't see that here.", "You don't see that here."))
+		failCommand(state)
+		return
+	}
+
+	// Check if item is in current room
+	if state.ItemLocations[itemNumber] != state.CurrentRoom {
+		fmt.Fprintln(state.Output, personal(state, "I don't see that here.", "You don't see that here."))
+		failCommand(state)
+		if state.Debug {
+			fmt.Fprintf(state.Output, "[DEBUG] Item %d is in room %d, not current room %d\n",
+				itemNumber, state.ItemLocations[itemNumber], state.CurrentRoom)
+		}
+		return
+	}
+
+	// Count carried items
+	carried := 0
+	for i, loc := range state.ItemLocations {
+		if i <= state.Header.NumItems && loc == CARRIED {
+			carried++
+		}
+	}
+
+	// Check if carrying too many items
+	if carried >= state.Header.MaxCarry {
+		fmt.Fprintln(state.Output, personal(state, "I'm carrying too much already.", "You are carrying too much already."))
+		failCommand(state)
+		return
+	}
+
+	// Pick up the item
+	state.ItemLocations[itemNumber] = CARRIED
+	fmt.Fprintf(state.Output, personal(state, "I'm now carrying the %s\n", "You are now carrying the %s\n"), getItemDescription(state, itemNumber))
+
+	if state.Debug {
+		fmt.Fprintf(state.Output, "[DEBUG] Picked up item %d, now in inventory\n", itemNumber)
+	}
+}
+
+// DropItem attempts to drop an item
+func DropItem(state *GameState, itemNumber int) {
+	// Check if item exists
+	if itemNumber < 0 || itemNumber > state.Header.NumItems {
+		fmt.Fprintln(state.Output, personal(state, "I don't have that.", "You don't have that."))
+		failCommand(state)
+		return
+	}
+
+	// Check if item is carried
+	if state.ItemLocations[itemNumber] != CARRIED {
+		fmt.Fprintln(state.Output, personal(state, "I don't have that.", "You don't have that."))
+		failCommand(state)
+		if state.Debug {
+			fmt.Fprintf(state.Output, "[DEBUG] Item %d is not carried, it's in room %d\n",
+				itemNumber, state.ItemLocations[itemNumber])
+		}
+		return
+	}
+
+	// Drop the item
+	state.ItemLocations[itemNumber] = state.CurrentRoom
+	fmt.Fprintf(state.Output, personal(state, "I've dropped the %s\n", "You have dropped the %s\n"), getItemDescription(state, itemNumber))
+
+	if state.Debug {
+		fmt.Fprintf(state.Output, "[DEBUG] Dropped item %d, now in room %d\n", itemNumber, state.CurrentRoom)
+	}
+}
+
+// getItemDescription returns a clean description of an item
+func getItemDescription(state *GameState, itemNumber int) string {
+	if itemNumber < 0 || itemNumber > state.Header.NumItems {
+		return "unknown item"
+	}
+
+	desc := state.Items[itemNumber].Description
+
+	// Remove any asterisks (used to mark treasures)
+	desc = strings.ReplaceAll(desc, "*", "")
+
+	// Remove any AutoGet part if present
+	if idx := strings.Index(desc, "/"); idx != -1 {
+		desc = desc[:idx]
+	}
+
+	return strings.TrimSpace(desc)
+}
+
+// MovePlayer attempts to move the player in a given direction. An exit to
+// a room that does not exist is treated as no exit.
+func MovePlayer(state *GameState, direction int) {
+	nextRoom := 0
+	if roomExists(state, state.CurrentRoom) {
+		nextRoom = state.Rooms[state.CurrentRoom].Exits[direction]
+	}
+	if !roomExists(state, nextRoom) {
+		if state.Debug {
+			fmt.Fprintf(state.Output, "[DEBUG] Exit leads to room %d, which does not exist\n", nextRoom)
+		}
+		nextRoom = 0
+	}
+
+	// Moving in the dark is dangerous
+	if IsDark(state) && fallInDark(state, nextRoom == 0) {
+		return
+	}
+
+	// Check if direction is valid
+	if nextRoom == 0 {
+		fmt.Fprintln(state.Output, personal(state, "I can't go that way.", "You can't go that way."))
+		failCommand(state)
+		return
+	}
+
+	// Move player to new room
+	state.CurrentRoom = nextRoom
+	state.DisplayedRoom = false
+	state.Stats.Moves++
+}
+
+// UpdateLightSource handles light source time limit. With PrehistoricLamp,
+// the default, an exhausted light source is destroyed as in the original
+// engine; without it the lamp stays where it is and only the light-out
+// flag is set. ScottLight counts down the last turns as the original did.
+func UpdateLightSource(state *GameState) {
+	// Only update if light source is carried, lit and not already out
+	lamp := state.Quirks.LightSource
+	if 0 <= lamp && lamp < len(state.ItemLocations) && state.ItemLocations[lamp] == CARRIED && !IsDark(state) && state.BitFlags&(1<<LIGHTOUTBIT) == 0 {
+		// Decrement light time
+		state.AltCounters[8]--
+
+		// Check if light has run out
+		if state.AltCounters[8] <= 0 {
+			state.BitFlags |= (1 << LIGHTOUTBIT)
+			fmt.Fprintln(state.Output, "Light has run out!")
+
+			// Move light source to room 0 (destroyed)
+			if state.Options.PrehistoricLamp {
+				state.ItemLocations[lamp] = DESTROYED
+			}
+		} else if state.Options.ScottLight && state.AltCounters[8] <= state.Quirks.Countdown {
+			fmt.Fprintf(state.Output, "Light runs out in %d turns.\n", state.AltCounters[8])
+		} else if !state.Options.ScottLight && state.AltCounters[8] <= state.Quirks.DimWarning {
+			// Warning when light is running low
+			fmt.Fprintln(state.Output, "Light is getting dim.")
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package adventure
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExhaustedLamp(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		keep      bool
+		wantPlace int
+	}{
+		{"destroyed by default", false, DESTROYED},
+		{"kept without PrehistoricLamp", true, CARRIED},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			state := compileTestGame(t, `
+lighttime 1
+lightsource lamp
+room a "room A"
+item lamp "Brass lamp"
+`)
+			var output strings.Builder
+			state.Output = &output
+			state.Options.PrehistoricLamp = !test.keep
+			state.ItemLocations[LIGHT_SOURCE] = CARRIED
+			state.AltCounters[8] = 1
+
+			UpdateLightSource(state)
+			if got := state.ItemLocations[LIGHT_SOURCE]; got != test.wantPlace {
+				t.Errorf("lamp at %d, want %d", got, test.wantPlace)
+			}
+			if state.BitFlags&(1<<LIGHTOUTBIT) == 0 {
+				t.Error("light-out flag not set")
+			}
+			if output.String() != "Light has run out!\n" {
+				t.Errorf("got message %q", output.String())
+			}
+		})
+	}
+}
+
+func TestEmptyInputIsNotATurn(t *testing.T) {
+	state := compileTestGame(t, `
+lighttime 10
+lightsource lamp
+room a "room A"
+item lamp "Brass lamp"
+	at carried
+occur
+	MSG "tick"
+`)
+	var output strings.Builder
+	RunGame(state, strings.NewReader("\n  \n\nLOOK\n\n"), &output)
+
+	if ticks := strings.Count(output.String(), "tick"); ticks != 2 {
+		t.Errorf("automatic actions ran %d times, want 2 (start and one turn)", ticks)
+	}
+	if state.Stats.Turns != 1 {
+		t.Errorf("counted %d turns, want 1", state.Stats.Turns)
+	}
+	if state.AltCounters[8] != 9 {
+		t.Errorf("light time %d, want 9", state.AltCounters[8])
+	}
+}
+
+func TestQuitShowsSummary(t *testing.T) {
+	state := compileTestGame(t, `
+room a "room A"
+`)
+	var output strings.Builder
+	RunGame(state, strings.NewReader("LOOK\nQUIT\n"), &output)
+
+	if state.Outcome != Quit {
+		t.Errorf("outcome %v, want quit", state.Outcome)
+	}
+	for _, want := range []string{"The game is now over: you quit.\n", "Turns: 2,", "Thanks for playing!\n"} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, output.String())
+		}
+	}
+}
+
+func TestGotoRoom255(t *testing.T) {
+	state := compileTestGame(t, `
+room a "room A"
+`)
+	if _, err := ExecuteCommand(state, CmdGOTO, []int{CARRIED}); err == nil {
+		t.Error("GOTO 255 in a game with two rooms did not fail")
+	}
+
+	// In a game that large, room 255 is a real room
+	state.Rooms = append(state.Rooms, make([]Room, 300)...)
+	if _, err := ExecuteCommand(state, CmdGOTO, []int{CARRIED}); err != nil {
+		t.Fatal(err)
+	}
+	if state.CurrentRoom != CARRIED {
+		t.Errorf("player in room %d, want 255", state.CurrentRoom)
+	}
+}
+
+func TestContinuationChain(t *testing.T) {
+	state := compileTestGame(t, `
+room a "room A"
+item rock "rock"
+action JUMP
+	MSG "jump"
+	CONT
+continuation when HAS rock
+	MSG "skipped"
+continuation
+	MSG "chained"
+action SING
+	MSG "sing"
+continuation
+	MSG "not reached"
+`)
+	var output strings.Builder
+	state.Output = &output
+	if err := ProcessCommand(state, "jump"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "jump\nchained\n"; output.String() != want {
+		t.Errorf("got %q, want %q", output.String(), want)
+	}
+	if state.ContinueFlag {
+		t.Error("CONT flag left set after the chain")
+	}
+}
+
+func TestContinuationChainGuard(t *testing.T) {
+	state := compileTestGame(t, `
+room a "room A"
+action JUMP
+	CONT
+continuation
+	MSG "again"
+`)
+	for i := 0; i < 2*maxContinuationChain; i++ {
+		state.Actions = append(state.Actions, state.Actions[1])
+	}
+	var output strings.Builder
+	state.Output = &output
+	last, err := RunContinuation(state, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs := strings.Count(output.String(), "again"); runs != maxContinuationChain || last != maxContinuationChain {
+		t.Errorf("chain ran %d entries ending at %d, want %d", runs, last, maxContinuationChain)
+	}
+}
+
+func TestAutomaticActionContinuation(t *testing.T) {
+	state := compileTestGame(t, `
+room a "room A"
+occur
+	MSG "auto"
+	CONT
+continuation
+	MSG "chained"
+occur
+	MSG "next"
+`)
+	var output strings.Builder
+	state.Output = &output
+	if err := ProcessAutomaticActions(state); err != nil {
+		t.Fatal(err)
+	}
+	if want := "auto\nchained\nnext\n"; output.String() != want {
+		t.Errorf("got %q, want %q", output.String(), want)
+	}
+}
@@ -0,0 +1,47 @@
+package adventure
+
+import (
+	"fmt"
+)
+
+// EngineError reports an action that cannot be carried out because one of
+// its parameters is out of range. The turn it happens in is abandoned.
+type EngineError struct {
+	Action int    // Index of the action being run
+	Title  string // The action's title, if it has one
+	Opcode string // Name of the condition or command
+	Value  int    // The offending parameter or code
+	Msg    string
+}
+
+func (e *EngineError) Error() string {
+	action := fmt.Sprintf("action %d", e.Action)
+	if e.Title != "" {
+		action += fmt.Sprintf(" (%s)", e.Title)
+	}
+	return fmt.Sprintf("%s: %s %d: %s", action, e.Opcode, e.Value, e.Msg)
+}
+
+// newEngineError builds an EngineError for the action being run
+func newEngineError(state *GameState, opcode string, value int, msg string) *EngineError {
+	err := &EngineError{Action: state.CurrentAction, Opcode: opcode, Value: value, Msg: msg}
+	if state.CurrentAction >= 0 && state.CurrentAction < len(state.ActionTitles) {
+		err.Title = state.ActionTitles[state.CurrentAction]
+	}
+	return err
+}
+
+// checkArgument returns an EngineError if a parameter refers to an item,
+// room, flag, counter, register or message that does not exist
+func checkArgument(state *GameState, opcode string, kind ArgKind, value int) error {
+	if limit, ok := argumentInRange(state, kind, value); !ok {
+		return newEngineError(state, opcode, value, fmt.Sprintf("%s does not exist (0-%d)", argKindNames[kind], limit))
+	}
+	return nil
+}
+
+// reportEngineError tells the player a turn was abandoned
+func reportEngineError(state *GameState, err error) {
+	fmt.Fprintf(state.Output, "Engine error: %v\n", err)
+	state.ContinueFlag = false
+}
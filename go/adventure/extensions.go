@@ -0,0 +1,91 @@
+package adventure
+
+import (
+	"fmt"
+)
+
+// CommandFunc implements a custom command. It receives the parameters the
+// command takes, in order, from the action's PAR conditions. Returning an
+// error abandons the turn as an EngineError from a built-in command does;
+// an error that is not an EngineError is reported as one for the action.
+type CommandFunc func(state *GameState, params []int) error
+
+// ConditionFunc implements a custom condition, reporting whether it holds
+// for the condition's parameter
+type ConditionFunc func(state *GameState, parameter int) bool
+
+// Extensions holds the custom commands and conditions registered on a game.
+// The zero value has none registered.
+type Extensions struct {
+	commands   map[int]extensionCommand
+	conditions map[int]extensionCondition
+}
+
+// extensionCommand is a registered command with its description
+type extensionCommand struct {
+	op Opcode
+	fn CommandFunc
+}
+
+// extensionCondition is a registered condition with its description
+type extensionCondition struct {
+	op Opcode
+	fn ConditionFunc
+}
+
+// RegisterCommand adds a command for code, usually one of the undefined
+// codes 89-101. A code from 52-88 replaces the built-in command. The
+// opcode gives the command's name and the parameters it takes.
+func (e *Extensions) RegisterCommand(code int, op Opcode, fn CommandFunc) error {
+	if code < CmdFirstOpcode || code > CmdLastOpcode {
+		return fmt.Errorf("command code %d is outside %d-%d", code, CmdFirstOpcode, CmdLastOpcode)
+	}
+	if fn == nil {
+		return fmt.Errorf("command %d has no function", code)
+	}
+	if e.commands == nil {
+		e.commands = map[int]extensionCommand{}
+	}
+	e.commands[code] = extensionCommand{op, fn}
+	return nil
+}
+
+// RegisterCondition replaces the condition for code 1-19. Every condition
+// code is already defined by the format, so a custom condition takes the
+// place of one the game does not use. The opcode may take at most one
+// parameter.
+func (e *Extensions) RegisterCondition(code int, op Opcode, fn ConditionFunc) error {
+	if code < CondHAS || code >= len(Conditions) {
+		return fmt.Errorf("condition code %d is outside %d-%d", code, CondHAS, len(Conditions)-1)
+	}
+	if len(op.Args) > 1 {
+		return fmt.Errorf("condition %s takes %d parameters; conditions take at most one", op.Name, len(op.Args))
+	}
+	if fn == nil {
+		return fmt.Errorf("condition %d has no function", code)
+	}
+	if e.conditions == nil {
+		e.conditions = map[int]extensionCondition{}
+	}
+	e.conditions[code] = extensionCondition{op, fn}
+	return nil
+}
+
+// Command returns the opcode for a command code, from the registered
+// commands first and then the built-in ones
+func (e *Extensions) Command(code int) (Opcode, bool) {
+	if ext, ok := e.commands[code]; ok {
+		return ext.op, true
+	}
+	op, ok := Commands[code]
+	return op, ok
+}
+
+// Condition returns the opcode for a condition code, from the registered
+// conditions first and then the built-in ones
+func (e *Extensions) Condition(code int) Opcode {
+	if ext, ok := e.conditions[code]; ok {
+		return ext.op
+	}
+	return Conditions[code]
+}
@@ -0,0 +1,57 @@
+package adventure
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExtensionsRunInActions(t *testing.T) {
+	state := compileTestGame(t, `
+room a "room A"
+item rock "rock"
+	at a
+action JUMP when HAS rock
+	90
+	MSG "after the shout"
+action SING
+	91
+	MSG "after the failure"
+`)
+	var output strings.Builder
+	state.Output = &output
+
+	// Condition 1 (HAS) now holds for an item in the room instead
+	if err := state.Extensions.RegisterCondition(CondHAS, Opcode{"HERE", []ArgKind{ArgItem}},
+		func(s *GameState, item int) bool { return s.ItemLocations[item] == s.CurrentRoom }); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Extensions.RegisterCommand(90, Opcode{"SHOUT", nil}, func(s *GameState, _ []int) error {
+		output.WriteString("HEY!\n")
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Extensions.RegisterCommand(91, Opcode{"BREAK", nil}, func(*GameState, []int) error {
+		return errors.New("broken")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ProcessCommand(state, "jump"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "HEY!\nafter the shout\n"; output.String() != want {
+		t.Errorf("got %q, want %q", output.String(), want)
+	}
+
+	output.Reset()
+	err := ProcessCommand(state, "sing")
+	var engineErr *EngineError
+	if !errors.As(err, &engineErr) || engineErr.Opcode != "BREAK" || engineErr.Msg != "broken" {
+		t.Errorf("got error %v, want an EngineError from BREAK", err)
+	}
+	if strings.Contains(output.String(), "after the failure") {
+		t.Error("the action carried on after the command failed")
+	}
+}
@@ -0,0 +1,210 @@
+// Package adventure implements an interpreter for Scott Adams format
+// adventure games. All game input and output goes through the reader and
+// writer held by the GameState, so the engine can be embedded in other tools.
+package adventure
+
+import (
+	"bufio"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+)
+
+// Constants for special locations and flags
+const (
+	// Item locations
+	CARRIED   = 255 // Item is carried by player
+	DESTROYED = 0   // Item is in room 0 (not in game)
+
+	// Special item IDs
+	LIGHT_SOURCE = 9 // Usual item ID for the light source; see Quirks
+
+	// Special bit flags
+	DARKBIT     = 15 // Bit flag for darkness
+	LIGHTOUTBIT = 16 // Bit flag for light running out
+
+	// Direction constants
+	NORTH = 0
+	SOUTH = 1
+	EAST  = 2
+	WEST  = 3
+	UP    = 4
+	DOWN  = 5
+)
+
+// GameOutcome records how a game ended
+type GameOutcome int
+
+const (
+	Playing GameOutcome = iota // The game is still in progress
+	Won                        // The player won
+	Died                       // The player was killed (DEAD)
+	Quit                       // The player quit, or FINI ended the game without a complete score
+)
+
+// String returns the outcome as it reads after "you": won, died or quit
+func (o GameOutcome) String() string {
+	switch o {
+	case Won:
+		return "won"
+	case Died:
+		return "died"
+	case Quit:
+		return "quit"
+	}
+	return "are still playing"
+}
+
+// GameHeader contains configuration values for the game
+type GameHeader struct {
+	TextStorageBytes int // Number of bytes for text storage
+	NumItems         int // Highest numbered object
+	NumActions       int // Highest numbered action
+	NumWords         int // Number of vocabulary words
+	NumRooms         int // Highest numbered room
+	MaxCarry         int // Maximum items player can carry
+	PlayerRoom       int // Starting room for the player
+	Treasures        int // Number of treasures in the game
+	WordLength       int // Word length for vocabulary matching
+	LightTime        int // Turns of light available
+	NumMessages      int // Number of messages
+	TreasureRoom     int // Room where treasures should be stored
+	AdventureVersion int // Version number of the adventure
+	AdventureNumber  int // Unique identifier for the adventure
+}
+
+// Room represents a location in the game world
+type Room struct {
+	Exits       [6]int // North, South, East, West, Up, Down
+	Description string
+}
+
+// Item represents an object that can be manipulated
+type Item struct {
+	Description      string
+	Location         int
+	OriginalLocation int
+	AutoGet          string // Text after "/" in description
+}
+
+// Action represents a game action with conditions and commands
+type Action struct {
+	Verb       int
+	Noun       int
+	Conditions [5]int
+	Commands   [2]int
+}
+
+// Word represents a vocabulary word that can be used in the game
+type Word struct {
+	Word      string
+	IsSynonym bool   // True if this is a synonym (starts with *)
+	Type      string // "verb" or "noun"
+	Index     int    // Vocabulary number used by actions (resolved for synonyms)
+}
+
+// GameState represents the complete state of the game
+type GameState struct {
+	Header        GameHeader
+	Rooms         []Room
+	Items         []Item
+	Actions       []Action
+	Words         []Word // All vocabulary entries in file order
+	Verbs         []Word // Verb table, indexed by position
+	Nouns         []Word // Noun table, indexed by position
+	Messages      []string
+	ActionTitles  []string
+	CurrentRoom   int
+	ItemLocations []int
+	BitFlags      uint32 // 32 bit flags
+	Counter       int
+	AltCounters   [9]int // 0-7 are general, 8 is light time
+	AltRooms      [6]int // Alternate room registers
+	ContinueFlag  bool
+	DisplayedRoom bool          // Whether room has been displayed this turn
+	Debug         bool          // Enable debugging output
+	CurrentAction int           // Index of the action currently being executed
+	NounText      string        // Second word of the last command, as typed
+	LoadWarnings  []*LoadError  // Problems tolerated by a lenient load
+	Outcome       GameOutcome   // Playing until the game ends
+	Options       Options       // Interpreter display and lamp behaviour
+	Darkness      DarknessRules // What happens to a player without light
+	Quirks        Quirks        // Interpreter details for this game
+	Stats         Statistics    // What the player has done this game
+	Scoring       ScoreFunc     // Custom scoring; nil counts stored treasures
+	Extensions    Extensions    // Custom commands and conditions
+	Seed          int64         // Seed the random source was started from
+	Rand          *rand.Rand    // Random source for chance events; see SetSeed
+	random        *countingSource
+
+	Input  *bufio.Reader // Source of player input
+	Output io.Writer     // Destination for all game output
+}
+
+// NewGameState creates a new game state with default values and a random
+// source seeded from the clock
+func NewGameState() *GameState {
+	state := &GameState{
+		BitFlags:      0,
+		Counter:       0,
+		ContinueFlag:  false,
+		DisplayedRoom: false,
+		Debug:         false,
+		Darkness:      StandardDarkness,
+		Quirks:        DefaultQuirks,
+		Options:       Options{PrehistoricLamp: DefaultQuirks.PrehistoricLamp},
+		Input:         bufio.NewReader(os.Stdin),
+		Output:        os.Stdout,
+	}
+	SetSeed(state, time.Now().UnixNano())
+	return state
+}
+
+// ResetGame puts a game back to its starting position: items return to
+// their original locations, flags and counters are cleared, the light is
+// refilled and the player is moved to the starting room
+func ResetGame(state *GameState) {
+	for i := range state.ItemLocations {
+		if i < len(state.Items) {
+			state.ItemLocations[i] = state.Items[i].OriginalLocation
+		}
+	}
+	state.CurrentRoom = state.Header.PlayerRoom
+	state.BitFlags = 0
+	state.Counter = 0
+	state.AltCounters = [9]int{}
+	state.AltCounters[8] = state.Header.LightTime
+	state.AltRooms = [6]int{}
+	state.ContinueFlag = false
+	state.DisplayedRoom = false
+	state.NounText = ""
+	state.Outcome = Playing
+	state.Stats = Statistics{}
+}
+
+// roomExists reports whether a room number is one of the game's rooms
+func roomExists(state *GameState, room int) bool {
+	return room >= 0 && room < len(state.Rooms)
+}
+
+// SetIO directs the game's input and output to the given reader and writer
+func SetIO(state *GameState, in io.Reader, out io.Writer) {
+	if br, ok := in.(*bufio.Reader); ok {
+		state.Input = br
+	} else {
+		state.Input = bufio.NewReader(in)
+	}
+	state.Output = out
+}
+
+// readLine reads a single line of player input, without the line ending.
+// The returned error is io.EOF once input is exhausted.
+func readLine(state *GameState) (string, error) {
+	line, err := state.Input.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimSpace(line), err
+}
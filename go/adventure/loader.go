@@ -0,0 +1,462 @@
+package adventure
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// LoadOptions controls how a game database is located and read
+type LoadOptions struct {
+	Member string // Name of the database inside a zip archive, if not the only one
+
+	// Lenient accepts imperfect databases: a wrong checksum, a truncated
+	// file and out-of-range rooms become warnings in GameState.LoadWarnings
+	// and safe defaults are used instead
+	Lenient bool
+}
+
+// LoadGameData loads the game data from the specified file
+func LoadGameData(filename string) (*GameState, error) {
+	return LoadGameDataFile(filename, LoadOptions{})
+}
+
+// LoadGameDataFile loads the game data from the specified file, which may
+// be a plain, gzip-compressed or zip-archived database
+func LoadGameDataFile(filename string, opts LoadOptions) (*GameState, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read game file: %w", err)
+	}
+	defer file.Close()
+
+	state, err := LoadGameDataReader(file, opts)
+	return withFileName(state, err, filename)
+}
+
+// LoadGameDataFS loads the named game database from a file system, such as
+// one embedded with go:embed
+func LoadGameDataFS(fsys fs.FS, name string, opts LoadOptions) (*GameState, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read game file: %w", err)
+	}
+	defer file.Close()
+
+	state, err := LoadGameDataReader(file, opts)
+	return withFileName(state, err, name)
+}
+
+// withFileName records the database file name on a load error and on
+// every load warning
+func withFileName(state *GameState, err error, filename string) (*GameState, error) {
+	var loadErr *LoadError
+	if errors.As(err, &loadErr) {
+		loadErr.File = filename
+	}
+	if state != nil {
+		for _, warning := range state.LoadWarnings {
+			warning.File = filename
+		}
+	}
+	return state, err
+}
+
+// LoadGameDataReader loads the game data from r, unwrapping gzip and zip
+// compression automatically
+func LoadGameDataReader(r io.Reader, opts LoadOptions) (*GameState, error) {
+	// Read the entire content
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read game file: %w", err)
+	}
+
+	content, err := unwrapArchive(data, opts.Member)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseGameData(string(content), opts.Lenient)
+}
+
+// parseGameData builds a game state from the text of a database
+func parseGameData(content string, lenient bool) (*GameState, error) {
+	// Parse the content
+	tokens, err := tokenizeGameData(content)
+	if err != nil {
+		return nil, err
+	}
+
+	state := NewGameState()
+	r := &tokenReader{tokens: tokens, content: content}
+
+	// Read header values (first 12 values)
+	r.section = "header"
+	headerValues := make([]int, 12)
+	for i := 0; i < 12; i++ {
+		r.index = i
+		if headerValues[i], err = r.number("value"); err != nil {
+			return nil, err
+		}
+	}
+
+	// Set header values
+	state.Header = GameHeader{
+		TextStorageBytes: headerValues[0],
+		NumItems:         headerValues[1],
+		NumActions:       headerValues[2],
+		NumWords:         headerValues[3],
+		NumRooms:         headerValues[4],
+		MaxCarry:         headerValues[5],
+		PlayerRoom:       headerValues[6],
+		Treasures:        headerValues[7],
+		WordLength:       headerValues[8],
+		LightTime:        headerValues[9],
+		NumMessages:      headerValues[10],
+		TreasureRoom:     headerValues[11],
+	}
+	// Every counted entry takes at least one value, so a count larger than
+	// the values left cannot be right and would only make a huge table
+	left := len(r.tokens) - r.pos
+	for _, i := range []int{1, 2, 3, 4, 10} {
+		r.index = i
+		if headerValues[i] < 0 {
+			return nil, r.errorAt(r.tokens[i], "invalid count: %d", headerValues[i])
+		}
+		if headerValues[i] > left {
+			return nil, r.errorAt(r.tokens[i], "count %d is more than the %d values left in the file", headerValues[i], left)
+		}
+	}
+
+	// Past the header a lenient load can fill in anything that is missing
+	r.lenient = lenient
+
+	// Read actions (each action consists of 8 numbers)
+	r.section = "action"
+	state.Actions = make([]Action, state.Header.NumActions+1)
+	for i := 0; i <= state.Header.NumActions; i++ {
+		var action Action
+		r.index = i
+
+		// Read vocabulary value (verb/noun pair)
+		vocab, err := r.number("vocabulary")
+		if err != nil {
+			return nil, err
+		}
+		action.Verb = vocab / 150
+		action.Noun = vocab % 150
+
+		// Read 5 conditions
+		for j := 0; j < 5; j++ {
+			if action.Conditions[j], err = r.number(fmt.Sprintf("condition %d", j)); err != nil {
+				return nil, err
+			}
+		}
+
+		// Read 2 commands
+		for j := 0; j < 2; j++ {
+			if action.Commands[j], err = r.number(fmt.Sprintf("command %d", j)); err != nil {
+				return nil, err
+			}
+		}
+
+		state.Actions[i] = action
+	}
+
+	// Read vocabulary words (NumWords+1 quoted verb/noun pairs)
+	state.Words = make([]Word, 0, 2*(state.Header.NumWords+1))
+	state.Verbs = make([]Word, state.Header.NumWords+1)
+	state.Nouns = make([]Word, state.Header.NumWords+1)
+	for i := 0; i <= state.Header.NumWords; i++ {
+		for _, table := range []struct {
+			words    []Word
+			wordType string
+		}{{state.Verbs, "verb"}, {state.Nouns, "noun"}} {
+			r.section, r.index = table.wordType, i
+			wordText, err := r.text("word")
+			if err != nil {
+				return nil, err
+			}
+
+			word := parseWord(wordText, table.wordType, i, table.words)
+			table.words[i] = word
+			state.Words = append(state.Words, word)
+		}
+	}
+
+	// Read rooms (6 exit numbers followed by a quoted description)
+	r.section = "room"
+	state.Rooms = make([]Room, state.Header.NumRooms+1)
+	for i := 0; i <= state.Header.NumRooms; i++ {
+		var room Room
+		r.index = i
+
+		// Read 6 exit numbers (N, S, E, W, U, D)
+		for j := 0; j < 6; j++ {
+			if room.Exits[j], err = r.number(fmt.Sprintf("exit %d", j)); err != nil {
+				return nil, err
+			}
+			if lenient && (room.Exits[j] < 0 || room.Exits[j] > state.Header.NumRooms) {
+				r.warnf(r.last, "exit %d leads to missing room %d; removed", j, room.Exits[j])
+				room.Exits[j] = 0
+			}
+		}
+
+		// Read description (quoted string)
+		if room.Description, err = r.text("description"); err != nil {
+			return nil, err
+		}
+
+		state.Rooms[i] = room
+	}
+
+	// Read messages (quoted strings)
+	r.section = "message"
+	state.Messages = make([]string, state.Header.NumMessages+1)
+	for i := 0; i <= state.Header.NumMessages; i++ {
+		r.index = i
+		if state.Messages[i], err = r.text("text"); err != nil {
+			return nil, err
+		}
+	}
+
+	// Read items (quoted description followed by location number)
+	r.section = "item"
+	state.Items = make([]Item, state.Header.NumItems+1)
+	state.ItemLocations = make([]int, state.Header.NumItems+1)
+	for i := 0; i <= state.Header.NumItems; i++ {
+		var item Item
+		r.index = i
+
+		// Read description (quoted string)
+		if item.Description, err = r.text("description"); err != nil {
+			return nil, err
+		}
+
+		// Check for AutoGet word
+		parts := strings.Split(item.Description, "/")
+		if len(parts) > 1 {
+			item.Description = parts[0]
+			if len(parts) > 2 {
+				item.AutoGet = parts[1]
+			}
+		}
+
+		// Read location
+		loc, err := r.number("location")
+		if err != nil {
+			return nil, err
+		}
+		if lenient && loc != CARRIED && (loc < 0 || loc > state.Header.NumRooms) {
+			r.warnf(r.last, "location %d is not a room; item placed nowhere", loc)
+			loc = DESTROYED
+		}
+		item.Location = loc
+		item.OriginalLocation = loc
+		state.ItemLocations[i] = loc
+
+		state.Items[i] = item
+	}
+
+	// Read action titles (quoted strings)
+	state.ActionTitles = make([]string, state.Header.NumActions+1)
+	actionTitleCount := 0
+	for r.pos < len(r.tokens) && actionTitleCount <= state.Header.NumActions {
+		token := r.tokens[r.pos].Text
+		if !strings.HasPrefix(token, "\"") {
+			break // Not a quoted string, might be trailer information
+		}
+
+		state.ActionTitles[actionTitleCount] = token[1 : len(token)-1]
+		actionTitleCount++
+		r.pos++
+	}
+
+	// Read trailer information
+	r.section, r.index = "trailer", -1
+	// - Version
+	if state.Header.AdventureVersion, err = r.number("adventure version"); err != nil {
+		return nil, err
+	}
+
+	// - Adventure number
+	if state.Header.AdventureNumber, err = r.number("adventure number"); err != nil {
+		return nil, err
+	}
+
+	// - Checksum
+	checksum, err := r.number("checksum")
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify checksum. A lenient load that ran out of data has read the
+	// missing trailer values as 0 and already warned about the end of the
+	// file, so there is no checksum to compare.
+	expectedChecksum := (2 * state.Header.NumActions) + state.Header.NumItems + state.Header.AdventureVersion
+	truncated := r.pos > len(r.tokens)
+	if checksum != expectedChecksum && !truncated {
+		if !lenient {
+			return nil, r.errorAt(r.last, "checksum verification failed. Expected %d, got %d", expectedChecksum, checksum)
+		}
+		r.warnf(r.last, "checksum verification failed. Expected %d, got %d", expectedChecksum, checksum)
+	}
+
+	// Rooms the game starts in or scores in must exist
+	if lenient {
+		r.section, r.index = "header", 6
+		if state.Header.PlayerRoom < 0 || state.Header.PlayerRoom > state.Header.NumRooms {
+			r.warnf(r.tokens[6], "starting room %d does not exist; starting in room 1", state.Header.PlayerRoom)
+			state.Header.PlayerRoom = min(1, state.Header.NumRooms)
+		}
+		r.index = 11
+		if state.Header.TreasureRoom < 0 || state.Header.TreasureRoom > state.Header.NumRooms {
+			r.warnf(r.tokens[11], "treasure room %d does not exist; using the starting room", state.Header.TreasureRoom)
+			state.Header.TreasureRoom = state.Header.PlayerRoom
+		}
+	}
+	state.LoadWarnings = r.warnings
+
+	// Initialize game state
+	state.CurrentRoom = state.Header.PlayerRoom
+	state.AltCounters[8] = state.Header.LightTime // Initialize light time counter
+	ApplyQuirks(state, QuirksFor(state.Header, nil))
+
+	return state, nil
+}
+
+// parseWord decodes one vocabulary entry. A leading "*" marks a synonym of
+// the closest preceding non-synonym word in the same table, and its Index is
+// resolved to that word so actions and the parser see a single number.
+func parseWord(text string, wordType string, index int, table []Word) Word {
+	word := Word{Word: text, Type: wordType, Index: index}
+	if !strings.HasPrefix(text, "*") {
+		return word
+	}
+
+	word.IsSynonym = true
+	word.Word = text[1:] // Remove *
+	for j := index - 1; j >= 0; j-- {
+		if !table[j].IsSynonym {
+			word.Index = j
+			break
+		}
+	}
+
+	return word
+}
+
+// token is one value from a database with the position it starts at
+type token struct {
+	Text   string
+	Line   int // 1-based line number
+	Column int // 1-based byte column
+}
+
+// tokenizeGameData parses the game data content and returns a list of tokens
+// This handles multi-line quoted strings correctly
+func tokenizeGameData(content string) ([]token, error) {
+	var tokens []token
+	var currentToken strings.Builder
+	inQuotes := false
+	line, lineStart := 1, 0
+	startLine, startColumn := 0, 0
+
+	// begin records where the token being built starts
+	begin := func(i int) {
+		if currentToken.Len() == 0 {
+			startLine, startColumn = line, i-lineStart+1
+		}
+	}
+	// flush ends the token being built, if any
+	flush := func() {
+		if currentToken.Len() > 0 {
+			tokens = append(tokens, token{currentToken.String(), startLine, startColumn})
+			currentToken.Reset()
+		}
+	}
+
+	for i := 0; i < len(content); i++ {
+		char := content[i]
+
+		switch {
+		case char == '"':
+			// Start or end of a quoted string
+			if inQuotes {
+				// End of quoted string
+				currentToken.WriteByte(char)
+				flush()
+				inQuotes = false
+			} else {
+				// Start of quoted string, after any partial token
+				flush()
+				begin(i)
+				currentToken.WriteByte(char)
+				inQuotes = true
+			}
+
+		case inQuotes:
+			// Inside a quoted string - just add the character
+			currentToken.WriteByte(char)
+
+		case char == '\n' || char == '\r':
+			// End of line (outside quotes)
+			flush()
+
+		case char == '/' && i+1 < len(content) && content[i+1] == '/':
+			// Comment - skip to the end of the line
+			flush()
+			for i+1 < len(content) && content[i+1] != '\n' && content[i+1] != '\r' {
+				i++
+			}
+
+		case !unicode.IsSpace(rune(char)):
+			// Non-whitespace character
+			begin(i)
+			currentToken.WriteByte(char)
+
+		default:
+			// Whitespace character outside quotes
+			flush()
+		}
+
+		if char == '\n' {
+			line, lineStart = line+1, i+1
+		}
+	}
+
+	// Check if quotes are balanced
+	if inQuotes {
+		return nil, &LoadError{
+			Line:    startLine,
+			Column:  startColumn,
+			Index:   -1,
+			Snippet: sourceLineAt(content, startLine),
+			Msg:     "unbalanced quotes in game data",
+		}
+	}
+
+	// Add the last token if there is one
+	flush()
+
+	return tokens, nil
+}
+
+// sourceLineAt returns the text of a 1-based line, without its line ending
+func sourceLineAt(content string, line int) string {
+	for n := 1; n < line; n++ {
+		i := strings.IndexByte(content, '\n')
+		if i < 0 {
+			return ""
+		}
+		content = content[i+1:]
+	}
+	if i := strings.IndexByte(content, '\n'); i >= 0 {
+		content = content[:i]
+	}
+	return strings.TrimRight(content, "\r")
+}
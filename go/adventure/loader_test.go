@@ -0,0 +1,50 @@
+package adventure
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadWarningsNameTheFile(t *testing.T) {
+	_, data := loadTestGame(t, "cave.dat")
+	// Drop the trailer's checksum and adventure number
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	truncated := strings.Join(lines[:len(lines)-2], "\n") + "\n"
+	fsys := fstest.MapFS{"cave.dat": {Data: []byte(truncated)}}
+
+	state, err := LoadGameDataFS(fsys, "cave.dat", LoadOptions{Lenient: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.LoadWarnings) != 1 {
+		t.Fatalf("got warnings %v, want one for the end of the file", state.LoadWarnings)
+	}
+	warning := state.LoadWarnings[0]
+	if warning.File != "cave.dat" {
+		t.Errorf("warning names file %q", warning.File)
+	}
+	if warning.Line != len(lines)-2 || strings.TrimSpace(warning.Snippet) != "105" {
+		t.Errorf("warning at line %d with snippet %q, want the version on line %d", warning.Line, warning.Snippet, len(lines)-2)
+	}
+
+	if _, err := LoadGameDataReader(bytes.NewReader([]byte(truncated)), LoadOptions{}); err == nil {
+		t.Error("strict load of a truncated file succeeded")
+	}
+}
+
+func TestLoadRejectsImpossibleCounts(t *testing.T) {
+	for _, header := range []string{
+		"0 1 999999999999999 0 0 0 0 0 0 0 0 0",
+		"0 999999999999999 0 0 0 0 0 0 0 0 0 0",
+		"0 0 0 0 0 0 0 0 0 0 -1 0",
+	} {
+		_, err := LoadGameDataReader(strings.NewReader(header+"\n"), LoadOptions{Lenient: true})
+		var loadErr *LoadError
+		if !errors.As(err, &loadErr) || loadErr.Section != "header" {
+			t.Errorf("%s: got %v, want a header LoadError", header, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package adventure
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LoadError reports a malformed game database, pointing at the entry and
+// source position where reading failed
+type LoadError struct {
+	File    string // Database file name, when loaded from a file
+	Section string // "header", "action", "verb", "noun", "room", "message", "item" or "trailer"
+	Index   int    // Entry number within the section, or -1
+	Line    int    // 1-based line of the offending value
+	Column  int    // 1-based byte column of the offending value
+	Snippet string // Source line containing the offending value
+	Msg     string
+}
+
+func (e *LoadError) Error() string {
+	var b strings.Builder
+	if e.File != "" {
+		fmt.Fprintf(&b, "%s:", e.File)
+	}
+	fmt.Fprintf(&b, "%d:%d: ", e.Line, e.Column)
+	if e.Section != "" {
+		b.WriteString(e.Section)
+		if e.Index >= 0 {
+			fmt.Fprintf(&b, " %d", e.Index)
+		}
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Msg)
+
+	if e.Snippet != "" {
+		// Show the line with a caret under the offending column, keeping
+		// tabs so the caret lines up
+		fmt.Fprintf(&b, "\n\t%s\n\t", e.Snippet)
+		for i := 0; i < e.Column-1 && i < len(e.Snippet); i++ {
+			if e.Snippet[i] == '\t' {
+				b.WriteByte('\t')
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteByte('^')
+	}
+	return b.String()
+}
+
+// tokenReader walks the database tokens, remembering which entry is being
+// read so errors can name it
+type tokenReader struct {
+	tokens   []token
+	content  string
+	pos      int
+	section  string
+	index    int
+	last     token        // Most recently read token, or the end of the data
+	lenient  bool         // Read missing values as defaults instead of failing
+	warnings []*LoadError // Problems tolerated in lenient mode
+}
+
+// errorAt builds a LoadError for the current entry at a token's position
+func (r *tokenReader) errorAt(tok token, format string, args ...interface{}) *LoadError {
+	return &LoadError{
+		Section: r.section,
+		Index:   r.index,
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Snippet: sourceLineAt(r.content, tok.Line),
+		Msg:     fmt.Sprintf(format, args...),
+	}
+}
+
+// warnf records a tolerated problem at a token's position
+func (r *tokenReader) warnf(tok token, format string, args ...interface{}) {
+	r.warnings = append(r.warnings, r.errorAt(tok, format, args...))
+}
+
+// next returns the next token, or an error positioned at the end of the
+// data when there are none left. In lenient mode running out of data is
+// reported once as a warning and the caller gets an empty token instead.
+func (r *tokenReader) next(field string) (token, error) {
+	if r.pos >= len(r.tokens) {
+		// Point just past the last value, on the last line that has one
+		end := token{Line: strings.Count(strings.TrimRight(r.content, " \t\r\n"), "\n") + 1}
+		end.Column = len(sourceLineAt(r.content, end.Line)) + 1
+		r.last = end
+		if !r.lenient {
+			return end, r.errorAt(end, "unexpected end of file while reading %s", field)
+		}
+		if r.pos == len(r.tokens) {
+			r.warnf(end, "unexpected end of file while reading %s; using defaults for the rest", field)
+			r.pos++
+		}
+		return end, nil
+	}
+	tok := r.tokens[r.pos]
+	r.pos++
+	r.last = tok
+	return tok, nil
+}
+
+// number reads a numeric value
+func (r *tokenReader) number(field string) (int, error) {
+	tok, err := r.next(field)
+	if err != nil || tok.Text == "" {
+		return 0, err
+	}
+	val, err := strconv.Atoi(tok.Text)
+	if err != nil {
+		return 0, r.errorAt(tok, "invalid %s: %s", field, tok.Text)
+	}
+	return val, nil
+}
+
+// text reads a quoted string and returns it without its quotes
+func (r *tokenReader) text(field string) (string, error) {
+	tok, err := r.next(field)
+	if err != nil || tok.Text == "" {
+		return "", err
+	}
+	if !strings.HasPrefix(tok.Text, "\"") {
+		return "", r.errorAt(tok, "invalid %s format, expected quoted text: %s", field, tok.Text)
+	}
+	return tok.Text[1 : len(tok.Text)-1], nil
+}
@@ -0,0 +1,238 @@
+package adventure
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MapOptions controls what a map export includes
+type MapOptions struct {
+	Gotos bool // Add edges for actions that move the player with GOTOy
+}
+
+// mapEdge is one way of getting from one room to another
+type mapEdge struct {
+	From, To int // From is 0 for a GOTOy action usable in any room
+	Label    string
+	OneWay   bool // No exit leads back
+	Goto     bool // Made by an action rather than an exit
+}
+
+// roomMap is the graph drawn by the map exporters
+type roomMap struct {
+	state   *GameState
+	items   map[int][]string // Item descriptions by starting room
+	edges   []mapEdge
+	anyRoom bool // Some GOTOy action has no room condition
+}
+
+// buildRoomMap collects the exits, items and optional GOTOy edges of a game
+func buildRoomMap(state *GameState, opts MapOptions) *roomMap {
+	m := &roomMap{state: state, items: map[int][]string{}}
+	directions := []string{"north", "south", "east", "west", "up", "down"}
+	last := len(state.Rooms) - 1
+
+	for from := 1; from <= last; from++ {
+		for dir, to := range state.Rooms[from].Exits {
+			if to < 1 || to > last {
+				continue
+			}
+			m.edges = append(m.edges, mapEdge{
+				From:   from,
+				To:     to,
+				Label:  directions[dir],
+				OneWay: !m.hasExit(to, from),
+			})
+		}
+	}
+
+	for _, item := range state.Items {
+		if item.OriginalLocation != DESTROYED && item.Description != "" {
+			m.items[item.OriginalLocation] = append(m.items[item.OriginalLocation], item.Description)
+		}
+	}
+
+	if opts.Gotos {
+		m.addGotos()
+	}
+	return m
+}
+
+// hasExit reports whether any exit of a room leads to another
+func (m *roomMap) hasExit(from, to int) bool {
+	for _, exit := range m.state.Rooms[from].Exits {
+		if exit == to {
+			return true
+		}
+	}
+	return false
+}
+
+// addGotos adds an edge for every GOTOy command, starting from the rooms the
+// action requires with IN conditions
+func (m *roomMap) addGotos() {
+	last := len(m.state.Rooms) - 1
+	for _, action := range m.state.Actions {
+		params := ActionParameters(action)
+		for _, cmd := range ActionCommands(action) {
+			opcode, known := Commands[cmd]
+			if !known {
+				continue
+			}
+			var args []int
+			for range opcode.Args {
+				if len(params) > 0 {
+					args, params = append(args, params[0]), params[1:]
+				}
+			}
+			if cmd != CmdGOTO || len(args) != 1 || args[0] < 1 || args[0] > last {
+				continue
+			}
+
+			label := m.actionLabel(action)
+			from := m.actionRooms(action)
+			if len(from) == 0 {
+				m.anyRoom = true
+				from = []int{0}
+			}
+			for _, room := range from {
+				m.edges = append(m.edges, mapEdge{From: room, To: args[0], Label: label, Goto: true})
+			}
+		}
+	}
+}
+
+// actionRooms returns the rooms an action's IN conditions require
+func (m *roomMap) actionRooms(action Action) []int {
+	var rooms []int
+	for _, cond := range action.Conditions {
+		if code, parameter := DecodeCondition(cond); code == CondIN && parameter >= 1 && parameter < len(m.state.Rooms) {
+			rooms = append(rooms, parameter)
+		}
+	}
+	return rooms
+}
+
+// actionLabel names an action by its words, such as "CLIMB TREE"
+func (m *roomMap) actionLabel(action Action) string {
+	if action.Verb == 0 {
+		return "(automatic)"
+	}
+	label := vocabWord(m.state.Verbs, action.Verb)
+	if action.Noun != 0 {
+		label += " " + vocabWord(m.state.Nouns, action.Noun)
+	}
+	return label
+}
+
+// vocabWord returns a word from a vocabulary table, or its number
+func vocabWord(table []Word, index int) string {
+	if index >= 0 && index < len(table) && table[index].Word != "" {
+		return table[index].Word
+	}
+	return fmt.Sprint(index)
+}
+
+// roomLabel returns the lines shown for a room: number and description,
+// then the items that start there
+func (m *roomMap) roomLabel(room int) []string {
+	desc := strings.TrimPrefix(m.state.Rooms[room].Description, "*")
+	lines := []string{fmt.Sprintf("%d: %s", room, desc)}
+	if room == m.state.Header.TreasureRoom {
+		lines = append(lines, "(treasure room)")
+	}
+	return append(lines, m.items[room]...)
+}
+
+// edgeLabel returns the text on an edge
+func (e mapEdge) edgeLabel() string {
+	if e.OneWay {
+		return e.Label + " (one-way)"
+	}
+	return e.Label
+}
+
+// WriteDOT writes the room graph in Graphviz DOT format
+func WriteDOT(w io.Writer, state *GameState, opts MapOptions) error {
+	m := buildRoomMap(state, opts)
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "digraph adventure_%d {\n", state.Header.AdventureNumber)
+	fmt.Fprintf(out, "\tnode [shape=box];\n")
+	for room := 1; room < len(state.Rooms); room++ {
+		attrs := ""
+		if room == state.Header.PlayerRoom {
+			attrs += ", style=bold"
+		}
+		if room == state.Header.TreasureRoom {
+			attrs += ", peripheries=2"
+		}
+		fmt.Fprintf(out, "\tr%d [label=%s%s];\n", room, dotQuote(strings.Join(m.roomLabel(room), "\n")), attrs)
+	}
+	if m.anyRoom {
+		fmt.Fprintf(out, "\tr0 [label=\"(any room)\", shape=ellipse];\n")
+	}
+	if carried := m.items[CARRIED]; len(carried) > 0 {
+		fmt.Fprintf(out, "\tcarried [label=%s, shape=note];\n", dotQuote(strings.Join(append([]string{"Carried"}, carried...), "\n")))
+	}
+
+	for _, e := range m.edges {
+		attrs := ""
+		switch {
+		case e.Goto:
+			attrs = ", style=dashed"
+		case e.OneWay:
+			attrs = ", color=red"
+		}
+		fmt.Fprintf(out, "\tr%d -> r%d [label=%s%s];\n", e.From, e.To, dotQuote(e.edgeLabel()), attrs)
+	}
+	fmt.Fprintf(out, "}\n")
+
+	return out.Flush()
+}
+
+// dotQuote quotes a DOT string, turning line breaks into centred lines
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
+
+// WriteMermaid writes the room graph as a Mermaid flowchart
+func WriteMermaid(w io.Writer, state *GameState, opts MapOptions) error {
+	m := buildRoomMap(state, opts)
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "flowchart TD\n")
+	for room := 1; room < len(state.Rooms); room++ {
+		fmt.Fprintf(out, "\tr%d[%s]\n", room, mermaidQuote(strings.Join(m.roomLabel(room), "\n")))
+	}
+	if m.anyRoom {
+		fmt.Fprintf(out, "\tr0([\"(any room)\"])\n")
+	}
+	if carried := m.items[CARRIED]; len(carried) > 0 {
+		fmt.Fprintf(out, "\tcarried[/%s/]\n", mermaidQuote(strings.Join(append([]string{"Carried"}, carried...), "\n")))
+	}
+
+	for _, e := range m.edges {
+		arrow := "-->"
+		if e.Goto {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(out, "\tr%d %s|%s| r%d\n", e.From, arrow, mermaidQuote(e.edgeLabel()), e.To)
+	}
+
+	if room := state.Header.TreasureRoom; room >= 1 && room < len(state.Rooms) {
+		fmt.Fprintf(out, "\tstyle r%d stroke-width:4px\n", room)
+	}
+
+	return out.Flush()
+}
+
+// mermaidQuote quotes Mermaid text, using entity codes for quotes and <br>
+// for line breaks
+func mermaidQuote(s string) string {
+	s = strings.NewReplacer(`"`, "#quot;", "\r", "", "\n", "<br>").Replace(s)
+	return `"` + s + `"`
+}
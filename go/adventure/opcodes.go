@@ -0,0 +1,242 @@
+package adventure
+
+// Condition codes (condition value = parameter*20 + code)
+const (
+	CondPAR   = 0
+	CondHAS   = 1
+	CondINW   = 2
+	CondAVL   = 3
+	CondIN    = 4
+	CondNINW  = 5
+	CondNHAVE = 6
+	CondNIN   = 7
+	CondBIT   = 8
+	CondNBIT  = 9
+	CondANY   = 10
+	CondNANY  = 11
+	CondNAVL  = 12
+	CondNRM0  = 13
+	CondRM0   = 14
+	CondCTLE  = 15
+	CondCTGT  = 16
+	CondORIG  = 17
+	CondNORIG = 18
+	CondCTEQ  = 19
+)
+
+// Command code ranges (command pair value = cmd1*150 + cmd2)
+const (
+	CmdFirstMessage     = 1   // Messages 1-51 are shown by their own number
+	CmdLastLowMessage   = 51  // Last message in the low range
+	CmdFirstOpcode      = 52  // First real command
+	CmdLastOpcode       = 101 // Last code reserved for commands (89-101 undefined)
+	CmdFirstHighMessage = 102 // Messages 52-99 are encoded as 102-149
+	CmdLastHighMessage  = 149 // Highest encodable command value
+	MaxMessage          = 99  // Highest message number a command can show
+)
+
+// Command codes
+const (
+	CmdGET      = 52
+	CmdDROP     = 53
+	CmdGOTO     = 54
+	CmdDESTROY  = 55
+	CmdNIGHT    = 56
+	CmdDAY      = 57
+	CmdSET      = 58
+	CmdDESTROY2 = 59
+	CmdCLR      = 60
+	CmdDEAD     = 61
+	CmdMOVE     = 62
+	CmdFINI     = 63
+	CmdDSPRM    = 64
+	CmdSCORE    = 65
+	CmdINV      = 66
+	CmdSET0     = 67
+	CmdCLR0     = 68
+	CmdFILL     = 69
+	CmdCLS      = 70
+	CmdSAVE     = 71
+	CmdSWAP     = 72
+	CmdCONT     = 73
+	CmdAGET     = 74
+	CmdPUTWITH  = 75
+	CmdDSPRM2   = 76
+	CmdCTDEC    = 77
+	CmdDSPCT    = 78
+	CmdCTSET    = 79
+	CmdEXRM0    = 80
+	CmdEXCT     = 81
+	CmdCTADD    = 82
+	CmdCTSUB    = 83
+	CmdSAYW     = 84
+	CmdSAYWCR   = 85
+	CmdSAYCR    = 86
+	CmdEXROOM   = 87
+	CmdDELAY    = 88
+)
+
+// ArgKind describes what a condition or command parameter refers to
+type ArgKind int
+
+const (
+	ArgNone     ArgKind = iota
+	ArgItem             // Item number
+	ArgRoom             // Room number
+	ArgPlace            // Room number, or CARRIED for the player's inventory
+	ArgFlag             // Bit flag number
+	ArgNumber           // Plain number (counter value)
+	ArgCounter          // Alternate counter number
+	ArgRegister         // Alternate room register number
+	ArgMessage          // Message number
+)
+
+// Opcode names a condition or command and lists the parameters it takes
+type Opcode struct {
+	Name string
+	Args []ArgKind
+}
+
+// Conditions lists the condition opcodes by code, using the symbols from
+// the engine reference
+var Conditions = [20]Opcode{
+	CondPAR:   {"PAR", []ArgKind{ArgNumber}},
+	CondHAS:   {"HAS", []ArgKind{ArgItem}},
+	CondINW:   {"IN/W", []ArgKind{ArgItem}},
+	CondAVL:   {"AVL", []ArgKind{ArgItem}},
+	CondIN:    {"IN", []ArgKind{ArgRoom}},
+	CondNINW:  {"-IN/W", []ArgKind{ArgItem}},
+	CondNHAVE: {"-HAVE", []ArgKind{ArgItem}},
+	CondNIN:   {"-IN", []ArgKind{ArgRoom}},
+	CondBIT:   {"BIT", []ArgKind{ArgFlag}},
+	CondNBIT:  {"-BIT", []ArgKind{ArgFlag}},
+	CondANY:   {"ANY", nil},
+	CondNANY:  {"-ANY", nil},
+	CondNAVL:  {"-AVL", []ArgKind{ArgItem}},
+	CondNRM0:  {"-RM0", []ArgKind{ArgItem}},
+	CondRM0:   {"RM0", []ArgKind{ArgItem}},
+	CondCTLE:  {"CT<=", []ArgKind{ArgNumber}},
+	CondCTGT:  {"CT>", []ArgKind{ArgNumber}},
+	CondORIG:  {"ORIG", []ArgKind{ArgItem}},
+	CondNORIG: {"-ORIG", []ArgKind{ArgItem}},
+	CondCTEQ:  {"CT=", []ArgKind{ArgNumber}},
+}
+
+// Commands lists the command opcodes 52-88 by code. Parameters are taken in
+// order from the action's PAR conditions.
+var Commands = map[int]Opcode{
+	CmdGET:      {"GETx", []ArgKind{ArgItem}},
+	CmdDROP:     {"DROPx", []ArgKind{ArgItem}},
+	CmdGOTO:     {"GOTOy", []ArgKind{ArgRoom}},
+	CmdDESTROY:  {"x->RM0", []ArgKind{ArgItem}},
+	CmdNIGHT:    {"NIGHT", nil},
+	CmdDAY:      {"DAY", nil},
+	CmdSET:      {"SETz", []ArgKind{ArgFlag}},
+	CmdDESTROY2: {"x->RM0", []ArgKind{ArgItem}},
+	CmdCLR:      {"CLRz", []ArgKind{ArgFlag}},
+	CmdDEAD:     {"DEAD", nil},
+	CmdMOVE:     {"x->y", []ArgKind{ArgItem, ArgPlace}},
+	CmdFINI:     {"FINI", nil},
+	CmdDSPRM:    {"DspRM", nil},
+	CmdSCORE:    {"SCORE", nil},
+	CmdINV:      {"INV", nil},
+	CmdSET0:     {"SET0", nil},
+	CmdCLR0:     {"CLR0", nil},
+	CmdFILL:     {"FILL", nil},
+	CmdCLS:      {"CLS", nil},
+	CmdSAVE:     {"SAVE", nil},
+	CmdSWAP:     {"EXx,x", []ArgKind{ArgItem, ArgItem}},
+	CmdCONT:     {"CONT", nil},
+	CmdAGET:     {"AGETx", []ArgKind{ArgItem}},
+	CmdPUTWITH:  {"BYx<-x", []ArgKind{ArgItem, ArgItem}},
+	CmdDSPRM2:   {"DspRM", nil},
+	CmdCTDEC:    {"CT-1", nil},
+	CmdDSPCT:    {"DspCT", nil},
+	CmdCTSET:    {"CT<-n", []ArgKind{ArgNumber}},
+	CmdEXRM0:    {"EXRM0", nil},
+	CmdEXCT:     {"EXm,CT", []ArgKind{ArgCounter}},
+	CmdCTADD:    {"CT+n", []ArgKind{ArgNumber}},
+	CmdCTSUB:    {"CT-n", []ArgKind{ArgNumber}},
+	CmdSAYW:     {"SAYw", nil},
+	CmdSAYWCR:   {"SAYwCR", nil},
+	CmdSAYCR:    {"SAYCR", nil},
+	CmdEXROOM:   {"EXc,CR", []ArgKind{ArgRegister}},
+	CmdDELAY:    {"DELAY", nil},
+}
+
+// MessageOpcode is the pseudo-opcode used for commands that show a message
+var MessageOpcode = Opcode{"MSG", []ArgKind{ArgMessage}}
+
+// ConditionCode looks up a condition by its symbol
+func ConditionCode(name string) (int, bool) {
+	for code, op := range Conditions {
+		if op.Name == name {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// CommandCode looks up a command by its symbol. Where two codes share a
+// symbol (x->RM0, DspRM) the lower, primary code is returned.
+func CommandCode(name string) (int, bool) {
+	for code := CmdFirstOpcode; code <= CmdLastOpcode; code++ {
+		if op, ok := Commands[code]; ok && op.Name == name {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// IsMessageCommand reports whether a command code shows a message
+func IsMessageCommand(cmd int) bool {
+	return (cmd >= CmdFirstMessage && cmd <= CmdLastLowMessage) ||
+		(cmd >= CmdFirstHighMessage && cmd <= CmdLastHighMessage)
+}
+
+// MessageNumber returns the message shown by a message command
+func MessageNumber(cmd int) int {
+	if cmd >= CmdFirstHighMessage {
+		return cmd - 50
+	}
+	return cmd
+}
+
+// MessageCommand returns the command code that shows a message
+func MessageCommand(msg int) int {
+	if msg > CmdLastLowMessage {
+		return msg + 50
+	}
+	return msg
+}
+
+// DecodeCondition splits a condition value into its code and parameter
+func DecodeCondition(value int) (code int, parameter int) {
+	return value % 20, value / 20
+}
+
+// EncodeCondition builds a condition value from its code and parameter
+func EncodeCondition(code int, parameter int) int {
+	return parameter*20 + code
+}
+
+// ActionCommands returns the four command codes of an action in execution
+// order, including empty (0) slots
+func ActionCommands(action Action) [4]int {
+	return [4]int{
+		action.Commands[0] / 150, action.Commands[0] % 150,
+		action.Commands[1] / 150, action.Commands[1] % 150,
+	}
+}
+
+// ActionParameters returns the parameters of an action's PAR conditions,
+// in the order commands consume them
+func ActionParameters(action Action) []int {
+	var params []int
+	for _, cond := range action.Conditions {
+		if code, parameter := DecodeCondition(cond); code == CondPAR {
+			params = append(params, parameter)
+		}
+	}
+	return params
+}
@@ -0,0 +1,54 @@
+package adventure
+
+import (
+	"strings"
+)
+
+// Options selects between the standard interpreter behaviours described in
+// the engine reference. Games start with the engine's own first-person
+// style and, from DefaultQuirks, PrehistoricLamp set, so a lamp that runs
+// out is destroyed as in the original engine.
+type Options struct {
+	YouAre          bool // Second-person phrasing ("You are in a ...")
+	TRS80Style      bool // The original room layout, with the room display wrapped to 64 columns
+	ScottLight      bool // Authentic light messages counting down the turns left
+	PrehistoricLamp bool // The light source is destroyed when it runs out (the default)
+	KeepPlaying     bool // Storing every treasure does not end the game
+}
+
+// Screen width used by TRS80Style
+const trs80Width = 64
+
+// Separator printed after the room in TRS80Style
+const trs80Line = "<------------------------------------------------------------>"
+
+// personal picks the first-person or, with YouAre, second-person form of
+// a message
+func personal(state *GameState, firstPerson, secondPerson string) string {
+	if state.Options.YouAre {
+		return secondPerson
+	}
+	return firstPerson
+}
+
+// wrapText breaks text into lines of at most width columns at spaces,
+// keeping existing line breaks
+func wrapText(text string, width int) string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			switch {
+			case line == "":
+				line = word
+			case len(line)+1+len(word) > width:
+				lines = append(lines, line)
+				line = word
+			default:
+				line += " " + word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,272 @@
+package adventure
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseCommand converts player input into verb/noun numbers
+func ParseCommand(state *GameState, words []string) (int, int) {
+	verb := 0
+	noun := 0
+
+	if len(words) > 0 {
+		verb = GetWordNumber(state, words[0], "verb")
+	}
+
+	if len(words) > 1 {
+		noun = GetWordNumber(state, words[1], "noun")
+	}
+
+	// GO takes any of the direction words, including single letters
+	if verb == 1 && len(words) > 1 { // GO
+		if dir := directionOf(state, words[1]); dir >= 0 {
+			noun = dir + 1
+			if state.Debug {
+				fmt.Fprintf(state.Output, "[DEBUG] GO direction mapped: %s -> %d\n", words[1], noun)
+			}
+		}
+	}
+
+	return verb, noun
+}
+
+// GetWordNumber returns the index of a word in the vocabulary
+func GetWordNumber(state *GameState, word string, wordType string) int {
+	// Truncate word to match game's word length
+	word = truncateWord(state, strings.ToUpper(word))
+
+	table := state.Verbs
+	if wordType == "noun" {
+		table = state.Nouns
+	}
+
+	// Check for exact match; synonyms already carry the index of their word.
+	// Entry 0 (AUT/ANY) is reserved and never matches player input.
+	for i := 1; i < len(table); i++ {
+		w := table[i]
+		if w.Word != "" && strings.EqualFold(truncateWord(state, w.Word), word) {
+			if state.Debug {
+				fmt.Fprintf(state.Output, "[DEBUG] Exact word match: '%s' -> %d ('%s')\n", word, w.Index, w.Word)
+			}
+			return w.Index
+		}
+	}
+
+	// Check for prefix match (Scott Adams only matches on first few letters)
+	for i := 1; i < len(table); i++ {
+		w := table[i]
+		if w.Word != "" && strings.HasPrefix(strings.ToUpper(w.Word), word) {
+			if state.Debug {
+				fmt.Fprintf(state.Output, "[DEBUG] Prefix word match: '%s' -> %d ('%s')\n", word, w.Index, w.Word)
+			}
+			return w.Index
+		}
+	}
+
+	if state.Debug {
+		fmt.Fprintf(state.Output, "[DEBUG] No match for word: '%s' (type: %s)\n", word, wordType)
+	}
+	return 0 // Not found
+}
+
+// directionOf returns the direction a word names: one of the direction
+// nouns 1-6 of the vocabulary, a synonym of one, or its single-letter
+// shortcut. It returns -1 for any other word.
+func directionOf(state *GameState, word string) int {
+	word = strings.ToUpper(word)
+	if noun := nounNumber(state, truncateWord(state, word)); noun >= 1 && noun <= 6 {
+		return noun - 1
+	}
+	if len(word) == 1 {
+		for dir, letter := range directionLetters(state) {
+			if letter == word {
+				return dir
+			}
+		}
+	}
+	return -1
+}
+
+// directionLetters returns the single-letter shortcuts for the six
+// directions: the first letter of each direction noun, left out where two
+// directions share a letter
+func directionLetters(state *GameState) [6]string {
+	var letters [6]string
+	count := map[string]int{}
+	for dir := range letters {
+		if dir+1 < len(state.Nouns) && state.Nouns[dir+1].Word != "" {
+			letters[dir] = strings.ToUpper(state.Nouns[dir+1].Word[:1])
+			count[letters[dir]]++
+		}
+	}
+	for dir, letter := range letters {
+		if count[letter] > 1 {
+			letters[dir] = ""
+		}
+	}
+	return letters
+}
+
+// truncateWord shortens a word to the game's significant word length
+func truncateWord(state *GameState, word string) string {
+	if state.Header.WordLength > 0 && len(word) > state.Header.WordLength {
+		return word[:state.Header.WordLength]
+	}
+	return word
+}
+
+// ProcessCommand handles player input, returning any EngineError raised
+// by the actions it runs
+func ProcessCommand(state *GameState, command string) error {
+	// Keep the noun as typed, for SAYw/SAYwCR
+	state.NounText = ""
+	if typed := strings.Fields(command); len(typed) > 1 {
+		state.NounText = typed[1]
+	}
+
+	// Convert to uppercase and split into words
+	command = strings.ToUpper(command)
+	words := strings.Fields(command)
+
+	if len(words) == 0 {
+		return nil
+	}
+
+	// Handle special commands
+	if words[0] == "I" || words[0] == "INV" || words[0] == "INVENTORY" {
+		DisplayInventory(state)
+		return nil
+	}
+
+	if words[0] == "LOOK" {
+		state.DisplayedRoom = false
+		return nil
+	}
+
+	if words[0] == "SAVE" {
+		SaveGame(state)
+		return nil
+	}
+
+	if words[0] == "LOAD" || (len(words) > 1 && words[0] == "RESTORE" && words[1] == "GAME") {
+		LoadGame(state)
+		return nil
+	}
+
+	if words[0] == "SCORE" {
+		DisplayScore(state)
+		return nil
+	}
+
+	if words[0] == "DEBUG" {
+		state.Debug = !state.Debug
+		fmt.Fprintf(state.Output, "Debug mode: %v\n", state.Debug)
+		return nil
+	}
+
+	if words[0] == "HELP" {
+		DisplayHelp(state)
+		return nil
+	}
+
+	// Handle single direction commands (e.g. "NORTH" instead of "GO NORTH")
+	if dir := directionOf(state, words[0]); dir >= 0 {
+		MovePlayer(state, dir)
+		return nil
+	}
+
+	// Parse input to get verb and noun
+	verb, noun := ParseCommand(state, words)
+
+	if state.Debug {
+		fmt.Fprintf(state.Output, "[DEBUG] Verb: %d, Noun: %d\n", verb, noun)
+	}
+
+	// Handle GO [direction] special case via action system
+	if verb == 1 { // GO
+		if noun >= 1 && noun <= 6 { // Direction nouns NORTH=1, SOUTH=2, etc.
+			if state.Debug {
+				fmt.Fprintf(state.Output, "[DEBUG] GO direction via action system: direction %d\n", noun-1)
+			}
+			MovePlayer(state, noun-1)
+			return nil
+		}
+	}
+
+	// Process actions with matching verb/noun
+	return ProcessActionsWithVerb(state, verb, noun)
+}
+
+// FindItemByName returns the item a noun names, preferring one that is
+// carried or in the current room, or -1 if no item has that word
+func FindItemByName(state *GameState, name string) int {
+	return findItem(state, name, CARRIED, state.CurrentRoom)
+}
+
+// findItem returns the item whose AutoGet word matches a noun, trying
+// items at each of the given locations in turn before any other. Words
+// are compared as the game compares them: cut to the word length, with a
+// synonym standing for its main word. Items are numbered from 0, so it
+// returns -1 if no item has that word.
+func findItem(state *GameState, name string, locations ...int) int {
+	var matches []int
+	for i, item := range state.Items {
+		if i < len(state.ItemLocations) && item.AutoGet != "" && sameNoun(state, item.AutoGet, name) {
+			matches = append(matches, i)
+		}
+	}
+
+	for _, location := range locations {
+		for _, item := range matches {
+			if state.ItemLocations[item] == location {
+				if state.Debug {
+					fmt.Fprintf(state.Output, "[DEBUG] Found item match: '%s' -> item %d in room %d\n", name, item, location)
+				}
+				return item
+			}
+		}
+	}
+	if len(matches) > 0 {
+		if state.Debug {
+			fmt.Fprintf(state.Output, "[DEBUG] Found item match: '%s' -> item %d elsewhere\n", name, matches[0])
+		}
+		return matches[0]
+	}
+
+	if state.Debug {
+		fmt.Fprintf(state.Output, "[DEBUG] No item match for: '%s'\n", name)
+	}
+	return -1 // Not found
+}
+
+// notAnItem answers GET or DROP of something that is not an item the
+// player can pick up
+func notAnItem(state *GameState) {
+	fmt.Fprintln(state.Output, personal(state, "It's beyond my power to do that.", "It's beyond your power to do that."))
+	failCommand(state)
+}
+
+// sameNoun reports whether two words name the same noun: equal once cut to
+// the word length, or forms of the same vocabulary entry
+func sameNoun(state *GameState, a, b string) bool {
+	a = truncateWord(state, strings.ToUpper(a))
+	b = truncateWord(state, strings.ToUpper(b))
+	if a == b {
+		return true
+	}
+	noun := nounNumber(state, a)
+	return noun != 0 && noun == nounNumber(state, b)
+}
+
+// nounNumber returns the vocabulary number of a truncated noun, following
+// synonyms, or 0 if it is not in the vocabulary
+func nounNumber(state *GameState, word string) int {
+	for i := 1; i < len(state.Nouns); i++ {
+		w := state.Nouns[i]
+		if w.Word != "" && strings.EqualFold(truncateWord(state, w.Word), word) {
+			return w.Index
+		}
+	}
+	return 0
+}
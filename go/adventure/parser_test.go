@@ -0,0 +1,45 @@
+package adventure
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestActionsOverrideGetAndDrop(t *testing.T) {
+	state := compileTestGame(t, `
+vocab verb 0 AUT GO "" "" "" "" "" "" "" ""
+vocab verb 10 GET "" "" "" "" "" "" "" DROP
+vocab noun 0 ANY NORTH SOUTH EAST WEST UP DOWN LAMP ROCK
+room a "room A"
+item lamp "Brass lamp"
+	called LAMP
+	at a
+item rock "Rock"
+	called ROCK
+	at a
+action GET LAMP
+	MSG "The lamp is bolted down."
+`)
+	for _, test := range []struct {
+		command string
+		want    string
+		item    int
+		place   int
+	}{
+		{"get lamp", "The lamp is bolted down.", 0, 1},
+		{"get rock", "now carrying", 1, CARRIED},
+		{"drop rock", "dropped", 1, 1},
+	} {
+		var output strings.Builder
+		state.Output = &output
+		if err := ProcessCommand(state, test.command); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(output.String(), test.want) {
+			t.Errorf("%s: got %q, want it to mention %q", test.command, output.String(), test.want)
+		}
+		if got := state.ItemLocations[test.item]; got != test.place {
+			t.Errorf("%s: item %d at %d, want %d", test.command, test.item, got, test.place)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package adventure
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Quirks are the details in which the interpreters for different games
+// differ. A game's quirks are chosen from its adventure number and version
+// when it is loaded; see QuirksFor.
+type Quirks struct {
+	LightSource     int    `yaml:"lightSource"`     // Item number of the light source
+	GetVerb         int    `yaml:"getVerb"`         // Verb that picks up an item when no action handles it
+	DropVerb        int    `yaml:"dropVerb"`        // Verb that drops an item when no action handles it
+	DimWarning      int    `yaml:"dimWarning"`      // Turns of light left when "Light is getting dim" starts
+	Countdown       int    `yaml:"countdown"`       // Turns of light left when the ScottLight countdown starts
+	PrehistoricLamp bool   `yaml:"prehistoricLamp"` // The light source is destroyed when it runs out
+	Darkness        string `yaml:"darkness"`        // Darkness rules, by name from DarknessRulesets
+}
+
+// DefaultQuirks are the engine's own behaviour, used for games the quirk
+// table does not list
+var DefaultQuirks = Quirks{
+	LightSource:     LIGHT_SOURCE,
+	GetVerb:         10,
+	DropVerb:        18,
+	DimWarning:      10,
+	Countdown:       24,
+	PrehistoricLamp: true,
+	Darkness:        "standard",
+}
+
+// QuirkProfile gives the quirks for a range of releases of one adventure
+type QuirkProfile struct {
+	Adventure  int    `yaml:"adventure"`  // Header.AdventureNumber
+	MinVersion int    `yaml:"minVersion"` // Lowest Header.AdventureVersion covered
+	MaxVersion int    `yaml:"maxVersion"` // Highest version covered, or 0 for no limit
+	Quirks     Quirks `yaml:",inline"`
+}
+
+// matches reports whether the profile covers a game
+func (p QuirkProfile) matches(header GameHeader) bool {
+	return p.Adventure == header.AdventureNumber &&
+		header.AdventureVersion >= p.MinVersion &&
+		(p.MaxVersion == 0 || header.AdventureVersion <= p.MaxVersion)
+}
+
+// QuirkTable holds the built-in profiles. None of the original games'
+// behaviour has been checked against their interpreters yet, so the table
+// is empty and automatic selection changes nothing: every game plays with
+// DefaultQuirks unless a quirk file read with ReadQuirkProfiles covers it.
+// A game's profile belongs here once its original behaviour is verified.
+var QuirkTable []QuirkProfile
+
+// QuirksFor picks the quirks for a game. A profile matches when its
+// adventure number is the game's and the game's version is within its
+// version range. The last matching entry of overrides wins, then the last
+// matching entry of QuirkTable; a game neither lists gets DefaultQuirks.
+func QuirksFor(header GameHeader, overrides []QuirkProfile) Quirks {
+	for _, table := range [][]QuirkProfile{overrides, QuirkTable} {
+		for i := len(table) - 1; i >= 0; i-- {
+			if table[i].matches(header) {
+				return table[i].Quirks
+			}
+		}
+	}
+	return DefaultQuirks
+}
+
+// ApplyQuirks sets a game to play with the given quirks. Darkness rules
+// not found in DarknessRulesets leave the game's rules unchanged.
+func ApplyQuirks(state *GameState, quirks Quirks) {
+	state.Quirks = quirks
+	state.Options.PrehistoricLamp = quirks.PrehistoricLamp
+	if rules, ok := DarknessRulesets[quirks.Darkness]; ok {
+		state.Darkness = rules
+	}
+}
+
+// ReadQuirkProfiles reads a list of quirk profiles from YAML or JSON.
+// Settings an entry leaves out keep the built-in value for the first
+// release the entry covers.
+func ReadQuirkProfiles(r io.Reader) ([]QuirkProfile, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the field names strictly before filling in the defaults
+	var check []QuirkProfile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&check); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to parse quirk profiles: %w", err)
+	}
+
+	var nodes []yaml.Node
+	if err := yaml.Unmarshal(data, &nodes); err != nil {
+		return nil, fmt.Errorf("failed to parse quirk profiles: %w", err)
+	}
+	profiles := make([]QuirkProfile, len(nodes))
+	for i, node := range nodes {
+		profile := check[i]
+		profile.Quirks = QuirksFor(GameHeader{AdventureNumber: profile.Adventure, AdventureVersion: profile.MinVersion}, nil)
+		if err := node.Decode(&profile); err != nil {
+			return nil, fmt.Errorf("quirk profile %d: %w", i, err)
+		}
+		for _, setting := range []struct {
+			name  string
+			value int
+		}{
+			{"lightSource", profile.Quirks.LightSource},
+			{"getVerb", profile.Quirks.GetVerb},
+			{"dropVerb", profile.Quirks.DropVerb},
+		} {
+			if setting.value < 0 {
+				return nil, fmt.Errorf("quirk profile %d: %s %d is negative", i, setting.name, setting.value)
+			}
+		}
+		if _, ok := DarknessRulesets[profile.Quirks.Darkness]; !ok {
+			return nil, fmt.Errorf("quirk profile %d: unknown darkness rules %q", i, profile.Quirks.Darkness)
+		}
+		profiles[i] = profile
+	}
+	return profiles, nil
+}
+
+// LoadQuirkFile reads quirk profiles from a file
+func LoadQuirkFile(filename string) ([]QuirkProfile, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadQuirkProfiles(file)
+}
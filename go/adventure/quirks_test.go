@@ -0,0 +1,71 @@
+package adventure
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuirksForVersionRange(t *testing.T) {
+	profiles, err := ReadQuirkProfiles(strings.NewReader(`
+- adventure: 5
+  maxVersion: 107
+  prehistoricLamp: false
+- adventure: 5
+  minVersion: 108
+  darkness: strict
+  dimWarning: 5
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		adventure, version int
+		prehistoric        bool
+		darkness           string
+		dimWarning         int
+	}{
+		{5, 100, false, "standard", 10},
+		{5, 107, false, "standard", 10},
+		{5, 108, true, "strict", 5},
+		{5, 200, true, "strict", 5},
+		{6, 100, true, "standard", 10},
+	} {
+		q := QuirksFor(GameHeader{AdventureNumber: test.adventure, AdventureVersion: test.version}, profiles)
+		if q.PrehistoricLamp != test.prehistoric || q.Darkness != test.darkness || q.DimWarning != test.dimWarning {
+			t.Errorf("adventure %d version %d: got %+v", test.adventure, test.version, q)
+		}
+		if q.LightSource != LIGHT_SOURCE || q.GetVerb != 10 || q.DropVerb != 18 {
+			t.Errorf("adventure %d version %d: settings left out lost their defaults: %+v", test.adventure, test.version, q)
+		}
+	}
+}
+
+func TestReadQuirkProfilesRejectsMistakes(t *testing.T) {
+	for _, text := range []string{
+		"- adventure: 1\n  dark: strict\n",
+		"- adventure: 1\n  darkness: gloomy\n",
+		"- adventure: 1\n  lightSource: -1\n",
+		"- adventure: 1\n  getVerb: -10\n",
+		"- adventure: 1\n  dropVerb: -18\n",
+	} {
+		if _, err := ReadQuirkProfiles(strings.NewReader(text)); err == nil {
+			t.Errorf("no error for %q", text)
+		}
+	}
+}
+
+func TestNegativeLightSource(t *testing.T) {
+	state := compileTestGame(t, `
+room a "room A"
+`)
+	state.Quirks.LightSource = -1
+	state.BitFlags |= 1 << DARKBIT
+	if !IsDark(state) {
+		t.Error("a game without a usable light source is not dark")
+	}
+	UpdateLightSource(state)
+	if _, err := ExecuteCommand(state, CmdFILL, nil); err == nil {
+		t.Error("FILL without a light source did not fail")
+	}
+}
@@ -0,0 +1,52 @@
+package adventure
+
+import (
+	"math/rand"
+)
+
+// countingSource is a random source that counts how many values it has
+// produced, so a restored game can continue the same sequence
+type countingSource struct {
+	src   rand.Source
+	draws int64
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.draws = 0
+}
+
+// SetSeed gives the game a fresh random source started from seed. Two runs
+// with the same seed and the same input play out identically.
+func SetSeed(state *GameState, seed int64) {
+	state.Seed = seed
+	state.random = &countingSource{src: rand.NewSource(seed)}
+	state.Rand = rand.New(state.random)
+}
+
+// randomDraws returns how many values the random source has produced
+func randomDraws(state *GameState) int64 {
+	if state.random == nil {
+		return 0
+	}
+	return state.random.draws
+}
+
+// maxRandomDraws limits how far a restored random source is advanced, so
+// a damaged save cannot stall the game. A few draws a turn stays far below.
+const maxRandomDraws = 1 << 24
+
+// restoreRandom restarts the random source from seed and advances it past
+// draws values, putting it back where a saved game left it. LoadGame
+// checks seed and draws first.
+func restoreRandom(state *GameState, seed int64, draws int64) {
+	SetSeed(state, seed)
+	for i := int64(0); i < draws; i++ {
+		state.random.Int63()
+	}
+}
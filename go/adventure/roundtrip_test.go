@@ -0,0 +1,202 @@
+package adventure
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+// loadTestGame loads a game database from testdata
+func loadTestGame(t *testing.T, name string) (*GameState, []byte) {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := LoadGameDataReader(bytes.NewReader(data), LoadOptions{})
+	if err != nil {
+		t.Fatalf("loading %s: %v", name, err)
+	}
+	return state, data
+}
+
+// writeDat writes a game state in the .dat format
+func writeDat(t *testing.T, state *GameState) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteGameData(&buf, state); err != nil {
+		t.Fatalf("writing game data: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// tokenTexts returns the values in a database, without their positions
+func tokenTexts(t *testing.T, data []byte) []string {
+	t.Helper()
+	tokens, err := tokenizeGameData(string(data))
+	if err != nil {
+		t.Fatalf("tokenizing: %v", err)
+	}
+	texts := make([]string, len(tokens))
+	for i, tok := range tokens {
+		texts[i] = tok.Text
+	}
+	return texts
+}
+
+// sameTokens reports the first difference between two token lists
+func sameTokens(t *testing.T, want, got []string) {
+	t.Helper()
+	for i := 0; i < len(want) && i < len(got); i++ {
+		if want[i] != got[i] {
+			t.Fatalf("token %d: want %q, got %q", i, want[i], got[i])
+		}
+	}
+	if len(want) != len(got) {
+		t.Fatalf("want %d tokens, got %d", len(want), len(got))
+	}
+}
+
+func TestDatRoundTrip(t *testing.T) {
+	state, data := loadTestGame(t, "cave.dat")
+	sameTokens(t, tokenTexts(t, data), tokenTexts(t, writeDat(t, state)))
+}
+
+func TestStructuredRoundTrip(t *testing.T) {
+	state, _ := loadTestGame(t, "cave.dat")
+	want := writeDat(t, state)
+
+	for _, format := range []struct {
+		name   string
+		export func(*bytes.Buffer, *GameState) error
+		load   func(*bytes.Buffer) (*GameState, error)
+	}{
+		{"structured", nil, nil},
+		{"JSON",
+			func(w *bytes.Buffer, s *GameState) error { return ExportJSON(w, s) },
+			func(r *bytes.Buffer) (*GameState, error) { return ImportJSON(r) }},
+		{"YAML",
+			func(w *bytes.Buffer, s *GameState) error { return ExportYAML(w, s) },
+			func(r *bytes.Buffer) (*GameState, error) { return ImportYAML(r) }},
+	} {
+		t.Run(format.name, func(t *testing.T) {
+			var back *GameState
+			var err error
+			if format.export == nil {
+				back, err = FromStructured(ToStructured(state))
+			} else {
+				var buf bytes.Buffer
+				if err := format.export(&buf, state); err != nil {
+					t.Fatalf("export: %v", err)
+				}
+				back, err = format.load(&buf)
+			}
+			if err != nil {
+				t.Fatalf("import: %v", err)
+			}
+			if got := writeDat(t, back); !bytes.Equal(want, got) {
+				sameTokens(t, tokenTexts(t, want), tokenTexts(t, got))
+				t.Fatal("databases differ in layout")
+			}
+		})
+	}
+}
+
+func TestDecompileRoundTrip(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		source string // Compiled first when set; otherwise cave.dat is used
+	}{
+		{name: "cave"},
+		{name: "sparse vocabulary", source: `
+vocab verb 0 AUT GO JUMP "" SING
+vocab noun 0 ANY NORTH "" "" "" "" ""
+room a "room A"
+	exit north a
+message "hello"
+action JUMP
+	MSG "whee"
+action SING NORTH
+	MSG "la"
+`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var state *GameState
+			if test.source == "" {
+				state, _ = loadTestGame(t, "cave.dat")
+			} else {
+				var err error
+				if state, err = Compile(strings.NewReader(test.source), "test.sck"); err != nil {
+					t.Fatalf("compile: %v", err)
+				}
+			}
+			want := writeDat(t, state)
+
+			var source bytes.Buffer
+			if err := Decompile(&source, state); err != nil {
+				t.Fatalf("decompile: %v", err)
+			}
+			back, err := Compile(&source, "decompiled.sck")
+			if err != nil {
+				t.Fatalf("compile decompiled source: %v", err)
+			}
+			if got := writeDat(t, back); !bytes.Equal(want, got) {
+				sameTokens(t, tokenTexts(t, want), tokenTexts(t, got))
+				t.Fatal("databases differ in layout")
+			}
+		})
+	}
+}
+
+func TestCompileTestSource(t *testing.T) {
+	source, err := os.Open("testdata/cave.sck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+	state, err := Compile(source, "cave.sck")
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	_, data := loadTestGame(t, "cave.dat")
+	sameTokens(t, tokenTexts(t, data), tokenTexts(t, writeDat(t, state)))
+	if problems := Validate(state); len(problems) > 0 {
+		t.Errorf("compiled game has problems: %v", problems)
+	}
+}
+
+func TestNegativeConditionRoundTrip(t *testing.T) {
+	state := compileTestGame(t, `
+room a "room A"
+action JUMP when IN a
+	MSG "whee"
+`)
+	state.Actions[0].Conditions[1] = -7
+	want := writeDat(t, state)
+
+	var buf bytes.Buffer
+	if err := ExportJSON(&buf, state); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	back, err := ImportJSON(&buf)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if got := writeDat(t, back); !bytes.Equal(want, got) {
+		sameTokens(t, tokenTexts(t, want), tokenTexts(t, got))
+		t.Fatal("databases differ in layout")
+	}
+
+	buf.Reset()
+	if err := Decompile(&buf, state); err != nil {
+		t.Fatalf("decompile: %v", err)
+	}
+	if back, err = Compile(&buf, "decompiled.sck"); err != nil {
+		t.Fatalf("compile decompiled source: %v", err)
+	}
+	if got := writeDat(t, back); !bytes.Equal(want, got) {
+		sameTokens(t, tokenTexts(t, want), tokenTexts(t, got))
+		t.Fatal("decompiled databases differ in layout")
+	}
+}
@@ -0,0 +1,204 @@
+package adventure
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// SaveGame saves the current game state
+func SaveGame(state *GameState) {
+	fmt.Fprint(state.Output, "Enter filename to save: ")
+	filename, _ := readLine(state)
+
+	if filename == "" {
+		filename = "adventure.sav"
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		fmt.Fprintf(state.Output, "Error creating save file: %v\n", err)
+		return
+	}
+	defer file.Close()
+
+	// Write game state
+	fmt.Fprintf(file, "%d\n", state.Header.AdventureNumber)
+	fmt.Fprintf(file, "%d\n", state.CurrentRoom)
+	fmt.Fprintf(file, "%d\n", state.Counter)
+	fmt.Fprintf(file, "%d\n", state.BitFlags)
+	fmt.Fprintf(file, "%d\n", state.AltCounters[8]) // Light time
+
+	// Write alternate rooms
+	for i := 0; i < 6; i++ {
+		fmt.Fprintf(file, "%d\n", state.AltRooms[i])
+	}
+
+	// Write alternate counters
+	for i := 0; i < 8; i++ {
+		fmt.Fprintf(file, "%d\n", state.AltCounters[i])
+	}
+
+	// Write item locations
+	for i := 0; i <= state.Header.NumItems; i++ {
+		fmt.Fprintf(file, "%d\n", state.ItemLocations[i])
+	}
+
+	// Write the random seed and how far the sequence has got, so a
+	// restored game makes the same chance rolls
+	fmt.Fprintf(file, "%d\n", state.Seed)
+	fmt.Fprintf(file, "%d\n", randomDraws(state))
+
+	// Write play statistics and the rooms visited
+	fmt.Fprintf(file, "%d\n", state.Stats.Turns)
+	fmt.Fprintf(file, "%d\n", state.Stats.Moves)
+	fmt.Fprintf(file, "%d\n", state.Stats.FailedCommands)
+	fmt.Fprintf(file, "%d\n", state.Stats.Deaths)
+	fmt.Fprintf(file, "%d\n", state.Stats.RoomsVisited())
+	for room, visited := range state.Stats.Visited {
+		if visited {
+			fmt.Fprintf(file, "%d\n", room)
+		}
+	}
+
+	fmt.Fprintln(state.Output, "Game saved.")
+}
+
+// LoadGame loads a saved game state, reporting whether it succeeded
+func LoadGame(state *GameState) bool {
+	fmt.Fprint(state.Output, "Enter filename to load: ")
+	filename, _ := readLine(state)
+
+	if filename == "" {
+		filename = "adventure.sav"
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		fmt.Fprintf(state.Output, "Error opening save file: %v\n", err)
+		return false
+	}
+	defer file.Close()
+
+	// A save rejected part way through leaves the game as it was
+	before := *state
+	before.ItemLocations = append([]int(nil), state.ItemLocations...)
+	loaded := false
+	defer func() {
+		if !loaded {
+			*state = before
+		}
+	}()
+
+	scanner := bufio.NewScanner(file)
+
+	// Read adventure number and verify
+	if !scanner.Scan() {
+		fmt.Fprintln(state.Output, "Error reading save file.")
+		return false
+	}
+	advNum, _ := strconv.Atoi(scanner.Text())
+	if advNum != state.Header.AdventureNumber {
+		fmt.Fprintln(state.Output, "This save file is for a different adventure.")
+		return false
+	}
+
+	// Read game state
+	if !scanner.Scan() {
+		fmt.Fprintln(state.Output, "Error reading save file.")
+		return false
+	}
+	room, _ := strconv.Atoi(scanner.Text())
+	if !roomExists(state, room) {
+		fmt.Fprintf(state.Output, "This save file puts you in room %d, which does not exist.\n", room)
+		return false
+	}
+	state.CurrentRoom = room
+
+	if !scanner.Scan() {
+		fmt.Fprintln(state.Output, "Error reading save file.")
+		return false
+	}
+	state.Counter, _ = strconv.Atoi(scanner.Text())
+
+	if !scanner.Scan() {
+		fmt.Fprintln(state.Output, "Error reading save file.")
+		return false
+	}
+	flags, _ := strconv.ParseUint(scanner.Text(), 10, 32)
+	state.BitFlags = uint32(flags)
+
+	if !scanner.Scan() {
+		fmt.Fprintln(state.Output, "Error reading save file.")
+		return false
+	}
+	state.AltCounters[8], _ = strconv.Atoi(scanner.Text())
+
+	// Read alternate rooms
+	for i := 0; i < 6; i++ {
+		if !scanner.Scan() {
+			fmt.Fprintln(state.Output, "Error reading save file.")
+			return false
+		}
+		state.AltRooms[i], _ = strconv.Atoi(scanner.Text())
+		if !roomExists(state, state.AltRooms[i]) {
+			fmt.Fprintf(state.Output, "This save file has room %d in register %d, which does not exist.\n", state.AltRooms[i], i)
+			return false
+		}
+	}
+
+	// Read alternate counters
+	for i := 0; i < 8; i++ {
+		if !scanner.Scan() {
+			fmt.Fprintln(state.Output, "Error reading save file.")
+			return false
+		}
+		state.AltCounters[i], _ = strconv.Atoi(scanner.Text())
+	}
+
+	// Read item locations
+	for i := 0; i <= state.Header.NumItems; i++ {
+		if !scanner.Scan() {
+			fmt.Fprintln(state.Output, "Error reading save file.")
+			return false
+		}
+		state.ItemLocations[i], _ = strconv.Atoi(scanner.Text())
+	}
+
+	// Read the random seed and position, which older saves do not have
+	if scanner.Scan() {
+		seed, _ := strconv.ParseInt(scanner.Text(), 10, 64)
+		var draws int64
+		if scanner.Scan() {
+			draws, _ = strconv.ParseInt(scanner.Text(), 10, 64)
+		}
+		if seed < 0 || draws < 0 || draws > maxRandomDraws {
+			fmt.Fprintf(state.Output, "This save file has a damaged random state (seed %d, %d draws).\n", seed, draws)
+			return false
+		}
+		restoreRandom(state, seed, draws)
+	}
+
+	// Read play statistics, which older saves do not have
+	state.Stats = Statistics{}
+	for _, count := range []*int{&state.Stats.Turns, &state.Stats.Moves, &state.Stats.FailedCommands, &state.Stats.Deaths} {
+		if scanner.Scan() {
+			*count, _ = strconv.Atoi(scanner.Text())
+		}
+	}
+	if scanner.Scan() {
+		visited, _ := strconv.Atoi(scanner.Text())
+		state.Stats.Visited = make([]bool, len(state.Rooms))
+		for i := 0; i < visited && scanner.Scan(); i++ {
+			if room, err := strconv.Atoi(scanner.Text()); err == nil && room >= 0 && room < len(state.Rooms) {
+				state.Stats.Visited[room] = true
+			}
+		}
+	}
+
+	fmt.Fprintln(state.Output, "Game loaded.")
+	state.DisplayedRoom = false
+	loaded = true
+	return true
+}
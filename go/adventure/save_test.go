@@ -0,0 +1,80 @@
+package adventure
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadSave runs LoadGame on a save file with the given lines, returning
+// whether it loaded and what it printed
+func loadSave(t *testing.T, state *GameState, lines ...string) (bool, string) {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "test.sav")
+	if err := os.WriteFile(filename, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var output strings.Builder
+	SetIO(state, strings.NewReader(filename+"\n"), &output)
+	return LoadGame(state), output.String()
+}
+
+func TestLoadGameRejectsMissingRooms(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		room     int
+		register int
+	}{
+		{"current room", 5, 0},
+		{"carried as current room", CARRIED, 0},
+		{"negative room", -1, 0},
+		{"room register", 0, 9},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			state := compileTestGame(t, `
+room a "room A"
+item rock "rock"
+	at a
+`)
+			lines := []string{"0", fmt.Sprint(test.room), "0", "0", "0", fmt.Sprint(test.register), "0", "0", "0", "0", "0"}
+			for i := 0; i < 8+len(state.ItemLocations); i++ {
+				lines = append(lines, "0")
+			}
+			if ok, output := loadSave(t, state, lines...); ok {
+				t.Errorf("save loaded: %q", output)
+			}
+			if state.CurrentRoom != state.Header.PlayerRoom {
+				t.Errorf("player moved to room %d", state.CurrentRoom)
+			}
+		})
+	}
+}
+
+func TestLoadGameRejectsDamagedRandomState(t *testing.T) {
+	for _, test := range []struct {
+		seed, draws string
+		ok          bool
+	}{
+		{"7", "3", true},
+		{"-7", "3", false},
+		{"7", "-3", false},
+		{"7", "99999999999999", false},
+	} {
+		state := compileTestGame(t, `
+room a "room A"
+`)
+		lines := []string{"0", "1"}
+		for i := 0; i < 17+len(state.ItemLocations); i++ {
+			lines = append(lines, "0")
+		}
+		lines = append(lines, test.seed, test.draws)
+		if ok, output := loadSave(t, state, lines...); ok != test.ok {
+			t.Errorf("seed %s, %s draws: loaded %v, want %v: %q", test.seed, test.draws, ok, test.ok, output)
+		}
+		if test.ok && (state.Seed != 7 || randomDraws(state) != 3) {
+			t.Errorf("random state is seed %d, %d draws; want seed 7, 3 draws", state.Seed, randomDraws(state))
+		}
+	}
+}
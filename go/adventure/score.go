@@ -0,0 +1,93 @@
+package adventure
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Score is the player's progress as reported by SCORE
+type Score struct {
+	Stored int // Treasures in the treasure room
+	Total  int // Treasures in the game, counted from the items
+	Rating int // Rating on a scale of 0 to 100
+}
+
+// Complete reports whether every treasure has been stored. A game without
+// treasures is never complete, so neither SCORE nor FINI counts it as won;
+// a custom ScoreFunc can decide otherwise.
+func (s Score) Complete() bool {
+	return s.Total > 0 && s.Stored >= s.Total
+}
+
+// ScoreFunc works out the player's score. Set GameState.Scoring to replace
+// the standard treasure count.
+type ScoreFunc func(state *GameState) Score
+
+// IsTreasure reports whether an item is a treasure, marked by a description
+// that starts with an asterisk
+func IsTreasure(item Item) bool {
+	return strings.HasPrefix(item.Description, "*")
+}
+
+// TreasureScore is the standard scoring: the share of the game's treasures
+// that are in the treasure room. Treasures are counted from the items
+// rather than taken from the header, so the rating cannot pass 100.
+func TreasureScore(state *GameState) Score {
+	score := Score{Stored: storedTreasures(state), Total: countTreasures(state)}
+	if score.Total > 0 {
+		score.Rating = score.Stored * 100 / score.Total
+	}
+	return score
+}
+
+// CurrentScore returns the player's score using the game's scoring function
+func CurrentScore(state *GameState) Score {
+	if state.Scoring != nil {
+		return state.Scoring(state)
+	}
+	return TreasureScore(state)
+}
+
+// DisplayScore shows the player's score. Once every treasure is stored the
+// game ends in a win, as in the original interpreters, unless KeepPlaying
+// is set.
+func DisplayScore(state *GameState) {
+	score := CurrentScore(state)
+
+	if score.Total == 0 {
+		fmt.Fprintln(state.Output, "There are no treasures to store in this adventure.")
+	} else {
+		fmt.Fprintf(state.Output, personal(state, "I've stored %d treasures.\n", "You have stored %d treasures.\n"), score.Stored)
+		fmt.Fprintf(state.Output, "On a scale of 0 to 100, that rates a %d.\n", score.Rating)
+	}
+	DisplayStatistics(state)
+
+	if score.Complete() {
+		fmt.Fprintln(state.Output, "Well done! You've found all the treasures!")
+		if !state.Options.KeepPlaying && state.Outcome == Playing {
+			state.Outcome = Won
+		}
+	}
+}
+
+// countTreasures counts the items that are treasures
+func countTreasures(state *GameState) int {
+	count := 0
+	for _, item := range state.Items {
+		if IsTreasure(item) {
+			count++
+		}
+	}
+	return count
+}
+
+// storedTreasures counts the treasures in the treasure room
+func storedTreasures(state *GameState) int {
+	treasureCount := 0
+	for i, loc := range state.ItemLocations {
+		if i < len(state.Items) && loc == state.Header.TreasureRoom && IsTreasure(state.Items[i]) {
+			treasureCount++
+		}
+	}
+	return treasureCount
+}
@@ -0,0 +1,68 @@
+package adventure
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// compileTestGame compiles adventure source for a test, discarding output
+func compileTestGame(t *testing.T, source string) *GameState {
+	t.Helper()
+	state, err := Compile(strings.NewReader(source), "test.sck")
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	state.Output = io.Discard
+	return state
+}
+
+func TestScoreCountsTreasureItems(t *testing.T) {
+	state := compileTestGame(t, `
+treasures 1
+room a "room A"
+item gem "*gem*"
+	at a
+item coin "*coin*"
+	at a
+item rock "rock with a * on it"
+	at a
+`)
+	score := TreasureScore(state)
+	if score.Total != 2 || score.Stored != 2 || score.Rating != 100 {
+		t.Errorf("got %+v, want 2 of 2 stored rated 100", score)
+	}
+	if !score.Complete() {
+		t.Error("score with every treasure stored is not complete")
+	}
+}
+
+func TestFiniOutcome(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		scoring ScoreFunc
+		want    GameOutcome
+	}{
+		{"no treasures", nil, Quit},
+		{"custom score complete", func(*GameState) Score { return Score{Stored: 1, Total: 1, Rating: 100} }, Won},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			state := compileTestGame(t, `
+room a "room A"
+action JUMP
+	MSG "You fall off the cliff."
+	FINI
+`)
+			state.Scoring = test.scoring
+			if score := CurrentScore(state); test.scoring == nil && (score.Total != 0 || score.Complete()) {
+				t.Errorf("got %+v, want no treasures and not complete", score)
+			}
+			if err := ProcessCommand(state, "jump"); err != nil {
+				t.Fatal(err)
+			}
+			if state.Outcome != test.want {
+				t.Errorf("outcome %v after FINI, want %v", state.Outcome, test.want)
+			}
+		})
+	}
+}
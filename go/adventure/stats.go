@@ -0,0 +1,52 @@
+package adventure
+
+import (
+	"fmt"
+)
+
+// Statistics counts what the player has done in the current game
+type Statistics struct {
+	Turns          int    // Commands entered
+	Moves          int    // Successful moves between rooms
+	FailedCommands int    // Commands that were not understood or could not be done
+	Deaths         int    // Times the player was killed
+	Visited        []bool // Rooms the player has been in, by room number
+}
+
+// visitRoom records that the player is in the current room
+func visitRoom(state *GameState) {
+	room := state.CurrentRoom
+	if room < 0 || room >= len(state.Rooms) {
+		return
+	}
+	if len(state.Stats.Visited) < len(state.Rooms) {
+		visited := make([]bool, len(state.Rooms))
+		copy(visited, state.Stats.Visited)
+		state.Stats.Visited = visited
+	}
+	state.Stats.Visited[room] = true
+}
+
+// RoomsVisited returns how many different rooms the player has been in
+func (s Statistics) RoomsVisited() int {
+	count := 0
+	for _, visited := range s.Visited {
+		if visited {
+			count++
+		}
+	}
+	return count
+}
+
+// failCommand counts a command that could not be carried out
+func failCommand(state *GameState) {
+	state.Stats.FailedCommands++
+}
+
+// DisplayStatistics shows the play statistics for the current game
+func DisplayStatistics(state *GameState) {
+	s := state.Stats
+	fmt.Fprintf(state.Output, "Turns: %d, moves: %d, failed commands: %d, deaths: %d.\n",
+		s.Turns, s.Moves, s.FailedCommands, s.Deaths)
+	fmt.Fprintf(state.Output, "Rooms visited: %d of %d.\n", s.RoomsVisited(), max(len(state.Rooms)-1, 0))
+}
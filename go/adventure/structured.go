@@ -0,0 +1,434 @@
+package adventure
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StructuredGame is a readable form of a game database, used for JSON and
+// YAML files. Header counts are implied by the lengths of the lists, and
+// conditions and commands are decoded into named operations.
+type StructuredGame struct {
+	Header   StructuredHeader   `json:"header" yaml:"header"`
+	Verbs    []string           `json:"verbs" yaml:"verbs"` // Synonyms start with "*"
+	Nouns    []string           `json:"nouns" yaml:"nouns"`
+	Rooms    []StructuredRoom   `json:"rooms" yaml:"rooms"`
+	Items    []StructuredItem   `json:"items" yaml:"items"`
+	Messages []string           `json:"messages" yaml:"messages"`
+	Actions  []StructuredAction `json:"actions" yaml:"actions"`
+}
+
+// StructuredHeader holds the header values that are not table sizes
+type StructuredHeader struct {
+	AdventureNumber  int `json:"adventureNumber" yaml:"adventureNumber"`
+	AdventureVersion int `json:"adventureVersion" yaml:"adventureVersion"`
+	TextStorageBytes int `json:"textStorageBytes" yaml:"textStorageBytes"`
+	MaxCarry         int `json:"maxCarry" yaml:"maxCarry"`
+	PlayerRoom       int `json:"playerRoom" yaml:"playerRoom"`
+	Treasures        int `json:"treasures" yaml:"treasures"`
+	WordLength       int `json:"wordLength" yaml:"wordLength"`
+	LightTime        int `json:"lightTime" yaml:"lightTime"`
+	TreasureRoom     int `json:"treasureRoom" yaml:"treasureRoom"`
+}
+
+// StructuredRoom is a room with its exits by direction name
+type StructuredRoom struct {
+	Description string          `json:"description" yaml:"description"`
+	Exits       StructuredExits `json:"exits" yaml:"exits"`
+}
+
+// StructuredExits lists the destination room for each direction (0 = none)
+type StructuredExits struct {
+	North int `json:"north,omitempty" yaml:"north,omitempty"`
+	South int `json:"south,omitempty" yaml:"south,omitempty"`
+	East  int `json:"east,omitempty" yaml:"east,omitempty"`
+	West  int `json:"west,omitempty" yaml:"west,omitempty"`
+	Up    int `json:"up,omitempty" yaml:"up,omitempty"`
+	Down  int `json:"down,omitempty" yaml:"down,omitempty"`
+}
+
+// StructuredItem is an item with its AutoGet word split out
+type StructuredItem struct {
+	Description string `json:"description" yaml:"description"`
+	AutoGet     string `json:"autoGet,omitempty" yaml:"autoGet,omitempty"`
+	Location    int    `json:"location" yaml:"location"`
+}
+
+// StructuredAction is an action with decoded conditions and commands.
+// Command parameters are given on the commands themselves; the PAR
+// conditions that carry them are generated when the action is encoded.
+type StructuredAction struct {
+	Title      string         `json:"title,omitempty" yaml:"title,omitempty"`
+	Verb       int            `json:"verb" yaml:"verb"`
+	Noun       int            `json:"noun" yaml:"noun"` // Percent chance when verb is 0
+	Conditions []StructuredOp `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+	Commands   []StructuredOp `json:"commands,omitempty" yaml:"commands,omitempty"`
+}
+
+// StructuredOp is a single condition or command. Op is the symbol from the
+// engine reference (HAS, GETx, ...) or MSG for a message. Code is only set
+// where the symbol is ambiguous or unknown; for an UNDEFINED condition it
+// is the whole condition value.
+type StructuredOp struct {
+	Op       string `json:"op" yaml:"op"`
+	Code     int    `json:"code,omitempty" yaml:"code,omitempty"`
+	Item     *int   `json:"item,omitempty" yaml:"item,omitempty"`
+	Other    *int   `json:"other,omitempty" yaml:"other,omitempty"` // Second item
+	Room     *int   `json:"room,omitempty" yaml:"room,omitempty"`
+	Flag     *int   `json:"flag,omitempty" yaml:"flag,omitempty"`
+	Value    *int   `json:"value,omitempty" yaml:"value,omitempty"`
+	Counter  *int   `json:"counter,omitempty" yaml:"counter,omitempty"`
+	Register *int   `json:"register,omitempty" yaml:"register,omitempty"`
+	Message  *int   `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// ExportJSON writes the game database as indented JSON
+func ExportJSON(w io.Writer, state *GameState) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(ToStructured(state))
+}
+
+// ExportYAML writes the game database as YAML
+func ExportYAML(w io.Writer, state *GameState) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(ToStructured(state)); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// ImportJSON loads a game database written by ExportJSON
+func ImportJSON(r io.Reader) (*GameState, error) {
+	var game StructuredGame
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&game); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON game: %w", err)
+	}
+	return FromStructured(&game)
+}
+
+// ImportYAML loads a game database written by ExportYAML
+func ImportYAML(r io.Reader) (*GameState, error) {
+	var game StructuredGame
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&game); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML game: %w", err)
+	}
+	return FromStructured(&game)
+}
+
+// ToStructured converts a loaded game database into its structured form
+func ToStructured(state *GameState) *StructuredGame {
+	game := &StructuredGame{
+		Header: StructuredHeader{
+			AdventureNumber:  state.Header.AdventureNumber,
+			AdventureVersion: state.Header.AdventureVersion,
+			TextStorageBytes: state.Header.TextStorageBytes,
+			MaxCarry:         state.Header.MaxCarry,
+			PlayerRoom:       state.Header.PlayerRoom,
+			Treasures:        state.Header.Treasures,
+			WordLength:       state.Header.WordLength,
+			LightTime:        state.Header.LightTime,
+			TreasureRoom:     state.Header.TreasureRoom,
+		},
+		Messages: append([]string(nil), state.Messages...),
+	}
+
+	for i := range state.Verbs {
+		game.Verbs = append(game.Verbs, encodeWord(state.Verbs[i]))
+		game.Nouns = append(game.Nouns, encodeWord(state.Nouns[i]))
+	}
+
+	for _, room := range state.Rooms {
+		game.Rooms = append(game.Rooms, StructuredRoom{
+			Description: room.Description,
+			Exits: StructuredExits{
+				North: room.Exits[NORTH],
+				South: room.Exits[SOUTH],
+				East:  room.Exits[EAST],
+				West:  room.Exits[WEST],
+				Up:    room.Exits[UP],
+				Down:  room.Exits[DOWN],
+			},
+		})
+	}
+
+	for _, item := range state.Items {
+		game.Items = append(game.Items, StructuredItem{
+			Description: item.Description,
+			AutoGet:     item.AutoGet,
+			Location:    item.OriginalLocation,
+		})
+	}
+
+	for i, action := range state.Actions {
+		sa := StructuredAction{Verb: action.Verb, Noun: action.Noun}
+		if i < len(state.ActionTitles) {
+			sa.Title = state.ActionTitles[i]
+		}
+
+		// Conditions other than PAR, which only carry command parameters
+		for _, cond := range action.Conditions {
+			code, parameter := DecodeCondition(cond)
+			if code == CondPAR {
+				continue
+			}
+			// A negative value has no condition code; keep it as it is
+			if code < 0 {
+				sa.Conditions = append(sa.Conditions, StructuredOp{Op: "UNDEFINED", Code: cond})
+				continue
+			}
+			op := StructuredOp{Op: Conditions[code].Name}
+			for n, kind := range Conditions[code].Args {
+				op.setArg(kind, n, parameter)
+			}
+			sa.Conditions = append(sa.Conditions, op)
+		}
+
+		// Commands, with their parameters taken from the PAR conditions
+		params := ActionParameters(action)
+		for _, cmd := range ActionCommands(action) {
+			if cmd == 0 {
+				continue
+			}
+			var op StructuredOp
+			switch opcode, known := Commands[cmd]; {
+			case IsMessageCommand(cmd):
+				op.Op = MessageOpcode.Name
+				op.setArg(ArgMessage, 0, MessageNumber(cmd))
+			case known:
+				op.Op = opcode.Name
+				if primary, _ := CommandCode(opcode.Name); primary != cmd {
+					op.Code = cmd
+				}
+				for n, kind := range opcode.Args {
+					parameter := 0
+					if len(params) > 0 {
+						parameter, params = params[0], params[1:]
+					}
+					op.setArg(kind, n, parameter)
+				}
+			default:
+				op.Op = "UNDEFINED"
+				op.Code = cmd
+			}
+			sa.Commands = append(sa.Commands, op)
+		}
+
+		game.Actions = append(game.Actions, sa)
+	}
+
+	return game
+}
+
+// FromStructured builds a playable game state from the structured form
+func FromStructured(game *StructuredGame) (*GameState, error) {
+	if len(game.Verbs) != len(game.Nouns) {
+		return nil, fmt.Errorf("vocabulary has %d verbs but %d nouns", len(game.Verbs), len(game.Nouns))
+	}
+	for _, table := range []struct {
+		name  string
+		count int
+	}{
+		{"verbs", len(game.Verbs)}, {"rooms", len(game.Rooms)}, {"items", len(game.Items)},
+		{"messages", len(game.Messages)}, {"actions", len(game.Actions)},
+	} {
+		if table.count == 0 {
+			return nil, fmt.Errorf("game has no %s (entry 0 is required)", table.name)
+		}
+	}
+
+	state := NewGameState()
+	state.Header = GameHeader{
+		TextStorageBytes: game.Header.TextStorageBytes,
+		NumItems:         len(game.Items) - 1,
+		NumActions:       len(game.Actions) - 1,
+		NumWords:         len(game.Verbs) - 1,
+		NumRooms:         len(game.Rooms) - 1,
+		MaxCarry:         game.Header.MaxCarry,
+		PlayerRoom:       game.Header.PlayerRoom,
+		Treasures:        game.Header.Treasures,
+		WordLength:       game.Header.WordLength,
+		LightTime:        game.Header.LightTime,
+		NumMessages:      len(game.Messages) - 1,
+		TreasureRoom:     game.Header.TreasureRoom,
+		AdventureVersion: game.Header.AdventureVersion,
+		AdventureNumber:  game.Header.AdventureNumber,
+	}
+
+	// Vocabulary, resolving synonyms as the loader does
+	state.Verbs = make([]Word, len(game.Verbs))
+	state.Nouns = make([]Word, len(game.Nouns))
+	for i := range game.Verbs {
+		state.Verbs[i] = parseWord(game.Verbs[i], "verb", i, state.Verbs)
+		state.Nouns[i] = parseWord(game.Nouns[i], "noun", i, state.Nouns)
+		state.Words = append(state.Words, state.Verbs[i], state.Nouns[i])
+	}
+
+	for _, room := range game.Rooms {
+		state.Rooms = append(state.Rooms, Room{
+			Description: room.Description,
+			Exits: [6]int{
+				room.Exits.North, room.Exits.South, room.Exits.East,
+				room.Exits.West, room.Exits.Up, room.Exits.Down,
+			},
+		})
+	}
+
+	for _, item := range game.Items {
+		state.Items = append(state.Items, Item{
+			Description:      item.Description,
+			Location:         item.Location,
+			OriginalLocation: item.Location,
+			AutoGet:          item.AutoGet,
+		})
+		state.ItemLocations = append(state.ItemLocations, item.Location)
+	}
+
+	state.Messages = append([]string(nil), game.Messages...)
+
+	for i, sa := range game.Actions {
+		action, err := encodeStructuredAction(sa)
+		if err != nil {
+			return nil, fmt.Errorf("action %d (%s): %w", i, sa.Title, err)
+		}
+		state.Actions = append(state.Actions, action)
+		state.ActionTitles = append(state.ActionTitles, sa.Title)
+	}
+
+	// Initialize game state
+	state.CurrentRoom = state.Header.PlayerRoom
+	state.AltCounters[8] = state.Header.LightTime // Initialize light time counter
+	ApplyQuirks(state, QuirksFor(state.Header, nil))
+
+	return state, nil
+}
+
+// encodeStructuredAction packs a structured action into the numeric format
+func encodeStructuredAction(sa StructuredAction) (Action, error) {
+	action := Action{Verb: sa.Verb, Noun: sa.Noun}
+	if sa.Noun < 0 || sa.Noun >= 150 || sa.Verb < 0 {
+		return action, fmt.Errorf("verb %d / noun %d cannot be encoded", sa.Verb, sa.Noun)
+	}
+
+	var conditions, params, commands []int
+	for _, op := range sa.Conditions {
+		if op.Op == "UNDEFINED" {
+			conditions = append(conditions, op.Code)
+			continue
+		}
+		code, ok := ConditionCode(op.Op)
+		if !ok {
+			return action, fmt.Errorf("unknown condition %q", op.Op)
+		}
+		if code == CondPAR {
+			return action, fmt.Errorf("PAR conditions are implied by command parameters")
+		}
+		parameter := 0
+		for n, kind := range Conditions[code].Args {
+			value, err := op.arg(kind, n)
+			if err != nil {
+				return action, err
+			}
+			parameter = value
+		}
+		conditions = append(conditions, EncodeCondition(code, parameter))
+	}
+
+	for _, op := range sa.Commands {
+		code := op.Code
+		if op.Op == MessageOpcode.Name {
+			msg, err := op.arg(ArgMessage, 0)
+			if err != nil {
+				return action, err
+			}
+			if msg < 0 || msg > MaxMessage {
+				return action, fmt.Errorf("message %d cannot be shown by a command (limit %d)", msg, MaxMessage)
+			}
+			code = MessageCommand(msg)
+		} else if code == 0 {
+			var ok bool
+			if code, ok = CommandCode(op.Op); !ok {
+				return action, fmt.Errorf("unknown command %q", op.Op)
+			}
+		}
+		for n, kind := range Commands[code].Args {
+			value, err := op.arg(kind, n)
+			if err != nil {
+				return action, err
+			}
+			params = append(params, value)
+		}
+		commands = append(commands, code)
+	}
+
+	for _, parameter := range params {
+		conditions = append(conditions, EncodeCondition(CondPAR, parameter))
+	}
+	if len(conditions) > len(action.Conditions) {
+		return action, fmt.Errorf("needs %d conditions including parameters (limit %d)",
+			len(conditions), len(action.Conditions))
+	}
+	if len(commands) > 4 {
+		return action, fmt.Errorf("has %d commands (limit 4)", len(commands))
+	}
+
+	copy(action.Conditions[:], conditions)
+	var slots [4]int
+	copy(slots[:], commands)
+	action.Commands = [2]int{slots[0]*150 + slots[1], slots[2]*150 + slots[3]}
+
+	return action, nil
+}
+
+// argField returns the field holding the n-th parameter of the given kind
+func (op *StructuredOp) argField(kind ArgKind, n int) **int {
+	switch kind {
+	case ArgItem:
+		if n > 0 {
+			return &op.Other
+		}
+		return &op.Item
+	case ArgRoom, ArgPlace:
+		return &op.Room
+	case ArgFlag:
+		return &op.Flag
+	case ArgCounter:
+		return &op.Counter
+	case ArgRegister:
+		return &op.Register
+	case ArgMessage:
+		return &op.Message
+	default:
+		return &op.Value
+	}
+}
+
+// setArg stores a decoded parameter
+func (op *StructuredOp) setArg(kind ArgKind, n int, value int) {
+	*op.argField(kind, n) = &value
+}
+
+// arg fetches a parameter, failing if it was left out
+func (op *StructuredOp) arg(kind ArgKind, n int) (int, error) {
+	field := *op.argField(kind, n)
+	if field == nil {
+		names := map[ArgKind]string{
+			ArgItem: "item", ArgRoom: "room", ArgPlace: "room", ArgFlag: "flag", ArgNumber: "value",
+			ArgCounter: "counter", ArgRegister: "register", ArgMessage: "message",
+		}
+		name := names[kind]
+		if kind == ArgItem && n > 0 {
+			name = "other"
+		}
+		return 0, fmt.Errorf("%s needs a %s", op.Op, name)
+	}
+	return *field, nil
+}
@@ -0,0 +1,179 @@
+package adventure
+
+import (
+	"fmt"
+)
+
+// Problem is an inconsistency found by Validate
+type Problem struct {
+	Section string // "header", "room", "item" or "action"
+	Index   int    // Entry number within the section, or -1
+	Msg     string
+}
+
+func (p Problem) String() string {
+	if p.Index < 0 {
+		return fmt.Sprintf("%s: %s", p.Section, p.Msg)
+	}
+	return fmt.Sprintf("%s %d: %s", p.Section, p.Index, p.Msg)
+}
+
+// Validate checks a loaded game for references the engine cannot follow,
+// such as exits to missing rooms, messages past the end of the table and
+// undefined commands, and returns every problem found
+func Validate(state *GameState) []Problem {
+	v := &validator{state: state}
+	v.header()
+	v.rooms()
+	v.items()
+	v.actions()
+	return v.problems
+}
+
+// validator collects problems while checking a game
+type validator struct {
+	state    *GameState
+	problems []Problem
+}
+
+// report records a problem with an entry
+func (v *validator) report(section string, index int, format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{section, index, fmt.Sprintf(format, args...)})
+}
+
+// lastRoom returns the highest room number
+func (v *validator) lastRoom() int {
+	return len(v.state.Rooms) - 1
+}
+
+// header checks the starting room, treasure room and treasure count
+func (v *validator) header() {
+	h := v.state.Header
+	if h.PlayerRoom < 1 || h.PlayerRoom > v.lastRoom() {
+		v.report("header", -1, "starting room %d does not exist (rooms are 1-%d)", h.PlayerRoom, v.lastRoom())
+	}
+	if h.TreasureRoom < 0 || h.TreasureRoom > v.lastRoom() {
+		v.report("header", -1, "treasure room %d does not exist (rooms are 0-%d)", h.TreasureRoom, v.lastRoom())
+	}
+
+	starred := countTreasures(v.state)
+	if h.Treasures != starred {
+		v.report("header", -1, "treasure count is %d but %d items are marked with *", h.Treasures, starred)
+	}
+}
+
+// rooms checks that every exit leads to a room
+func (v *validator) rooms() {
+	directions := []string{"north", "south", "east", "west", "up", "down"}
+	for i, room := range v.state.Rooms {
+		for dir, exit := range room.Exits {
+			if exit < 0 || exit > v.lastRoom() {
+				v.report("room", i, "exit %s leads to room %d, past the last room %d", directions[dir], exit, v.lastRoom())
+			}
+		}
+	}
+}
+
+// items checks that every item starts in a room, carried or nowhere
+func (v *validator) items() {
+	for i, item := range v.state.Items {
+		loc := item.OriginalLocation
+		if loc != CARRIED && (loc < 0 || loc > v.lastRoom()) {
+			v.report("item", i, "starts in room %d, past the last room %d", loc, v.lastRoom())
+		}
+	}
+}
+
+// actions checks each action's words, conditions and commands
+func (v *validator) actions() {
+	for i, action := range v.state.Actions {
+		if action.Verb > 0 && action.Verb >= len(v.state.Verbs) {
+			v.report("action", i, "verb %d is not in the vocabulary", action.Verb)
+		}
+		if action.Verb > 0 && action.Noun >= len(v.state.Nouns) {
+			v.report("action", i, "noun %d is not in the vocabulary", action.Noun)
+		}
+
+		for n, cond := range action.Conditions {
+			code, parameter := DecodeCondition(cond)
+			if cond < 0 {
+				v.report("action", i, "condition %d has negative value %d", n, cond)
+				continue
+			}
+			op := v.state.Extensions.Condition(code)
+			for _, kind := range op.Args {
+				v.argument(i, fmt.Sprintf("condition %d (%s)", n, op.Name), kind, parameter)
+			}
+		}
+
+		params := ActionParameters(action)
+		for n, cmd := range ActionCommands(action) {
+			opcode, known := v.state.Extensions.Command(cmd)
+			switch {
+			case cmd == 0:
+			case IsMessageCommand(cmd):
+				if msg := MessageNumber(cmd); msg >= len(v.state.Messages) {
+					v.report("action", i, "command %d shows message %d, past the last message %d", n, msg, len(v.state.Messages)-1)
+				}
+			case !known:
+				v.report("action", i, "command %d uses undefined code %d", n, cmd)
+			default:
+				for _, kind := range opcode.Args {
+					if len(params) == 0 {
+						v.report("action", i, "command %d (%s) has no parameter left to use", n, opcode.Name)
+						break
+					}
+					v.argument(i, fmt.Sprintf("command %d (%s)", n, opcode.Name), kind, params[0])
+					params = params[1:]
+				}
+			}
+		}
+	}
+}
+
+// argument checks a condition or command parameter against what it refers to
+func (v *validator) argument(action int, what string, kind ArgKind, value int) {
+	if limit, ok := argumentInRange(v.state, kind, value); !ok {
+		v.report("action", action, "%s refers to %s %d, outside 0-%d", what, argKindNames[kind], value, limit)
+	}
+}
+
+// argumentInRange reports whether a parameter refers to something that
+// exists, along with the highest value allowed
+func argumentInRange(state *GameState, kind ArgKind, value int) (int, bool) {
+	var limit int
+	switch kind {
+	case ArgItem:
+		limit = len(state.ItemLocations) - 1
+	case ArgPlace:
+		if value == CARRIED {
+			return CARRIED, true
+		}
+		limit = len(state.Rooms) - 1
+	case ArgRoom:
+		limit = len(state.Rooms) - 1
+	case ArgFlag:
+		limit = 31
+	case ArgCounter:
+		limit = len(state.AltCounters) - 1
+	case ArgRegister:
+		limit = len(state.AltRooms) - 1
+	case ArgMessage:
+		limit = len(state.Messages) - 1
+	default:
+		return value, true
+	}
+	return limit, value >= 0 && value <= limit
+}
+
+// Names of the things a parameter can refer to
+var argKindNames = map[ArgKind]string{
+	ArgItem:     "item",
+	ArgRoom:     "room",
+	ArgPlace:    "room",
+	ArgFlag:     "flag",
+	ArgNumber:   "number",
+	ArgCounter:  "counter",
+	ArgRegister: "room register",
+	ArgMessage:  "message",
+}
@@ -0,0 +1,37 @@
+package adventure
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRoomArguments(t *testing.T) {
+	for _, test := range []struct {
+		command string
+		problem string // Expected problem, or empty for none
+	}{
+		{"GOTOy a", ""},
+		{"GOTOy carried", "refers to room 255, outside 0-1"},
+		{"x->y rock carried", ""},
+		{"x->y rock nowhere", ""},
+		{"x->y rock 2", "refers to room 2, outside 0-1"},
+	} {
+		state := compileTestGame(t, `
+room a "room A"
+item rock "rock"
+	at a
+action JUMP
+	`+test.command+`
+`)
+		var found []string
+		for _, problem := range Validate(state) {
+			found = append(found, problem.String())
+		}
+		switch {
+		case test.problem == "" && len(found) > 0:
+			t.Errorf("%s: unexpected problems %q", test.command, found)
+		case test.problem != "" && (len(found) != 1 || !strings.Contains(found[0], test.problem)):
+			t.Errorf("%s: got problems %q, want one mentioning %q", test.command, found, test.problem)
+		}
+	}
+}
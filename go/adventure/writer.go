@@ -0,0 +1,146 @@
+package adventure
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteGameData writes the game database in the canonical .dat layout that
+// LoadGameData reads. Header counts and the trailer checksum are recomputed
+// from the tables, so a modified game always writes out as a loadable file.
+func WriteGameData(w io.Writer, state *GameState) error {
+	dw := &datWriter{w: bufio.NewWriter(w)}
+
+	header := state.Header
+	header.NumItems = len(state.Items) - 1
+	header.NumActions = len(state.Actions) - 1
+	header.NumWords = len(state.Verbs) - 1
+	header.NumRooms = len(state.Rooms) - 1
+	header.NumMessages = len(state.Messages) - 1
+
+	if len(state.Nouns) != len(state.Verbs) {
+		return fmt.Errorf("vocabulary has %d verbs but %d nouns", len(state.Verbs), len(state.Nouns))
+	}
+
+	// Header values
+	for _, value := range []int{
+		header.TextStorageBytes,
+		header.NumItems,
+		header.NumActions,
+		header.NumWords,
+		header.NumRooms,
+		header.MaxCarry,
+		header.PlayerRoom,
+		header.Treasures,
+		header.WordLength,
+		header.LightTime,
+		header.NumMessages,
+		header.TreasureRoom,
+	} {
+		dw.number(value)
+	}
+
+	// Actions (vocabulary, 5 conditions, 2 command pairs)
+	for _, action := range state.Actions {
+		dw.number(action.Verb*150 + action.Noun)
+		for _, cond := range action.Conditions {
+			dw.number(cond)
+		}
+		for _, cmd := range action.Commands {
+			dw.number(cmd)
+		}
+	}
+
+	// Vocabulary as interleaved verb/noun pairs
+	for i := range state.Verbs {
+		dw.text(encodeWord(state.Verbs[i]))
+		dw.text(encodeWord(state.Nouns[i]))
+	}
+
+	// Rooms (6 exits followed by the description)
+	for _, room := range state.Rooms {
+		for _, exit := range room.Exits {
+			dw.number(exit)
+		}
+		dw.text(room.Description)
+	}
+
+	// Messages
+	for _, msg := range state.Messages {
+		dw.text(msg)
+	}
+
+	// Items (description with /AUTOGET/ suffix, then starting location)
+	for _, item := range state.Items {
+		desc := item.Description
+		if item.AutoGet != "" {
+			desc += "/" + item.AutoGet + "/"
+		}
+		dw.item(desc, item.OriginalLocation)
+	}
+
+	// Action titles, one per action
+	for i := range state.Actions {
+		title := ""
+		if i < len(state.ActionTitles) {
+			title = state.ActionTitles[i]
+		}
+		dw.text(title)
+	}
+
+	// Trailer
+	dw.number(header.AdventureVersion)
+	dw.number(header.AdventureNumber)
+	dw.number(2*header.NumActions + header.NumItems + header.AdventureVersion)
+
+	if dw.err != nil {
+		return dw.err
+	}
+	return dw.w.Flush()
+}
+
+// encodeWord returns the vocabulary text for a word, marking synonyms
+func encodeWord(word Word) string {
+	if word.IsSynonym {
+		return "*" + word.Word
+	}
+	return word.Word
+}
+
+// datWriter writes .dat values one per line and keeps the first error
+type datWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// number writes a numeric value
+func (dw *datWriter) number(value int) {
+	if dw.err == nil {
+		_, dw.err = fmt.Fprintf(dw.w, " %d\n", value)
+	}
+}
+
+// text writes a quoted string
+func (dw *datWriter) text(s string) {
+	if dw.err == nil && dw.checkText(s) {
+		_, dw.err = fmt.Fprintf(dw.w, "\"%s\"\n", s)
+	}
+}
+
+// item writes an item description and its location on one line
+func (dw *datWriter) item(desc string, location int) {
+	if dw.err == nil && dw.checkText(desc) {
+		_, dw.err = fmt.Fprintf(dw.w, "\"%s\" %d\n", desc, location)
+	}
+}
+
+// checkText rejects strings that cannot be quoted in the .dat format
+func (dw *datWriter) checkText(s string) bool {
+	if strings.Contains(s, "\"") {
+		dw.err = fmt.Errorf("text cannot contain a double quote: %s", s)
+		return false
+	}
+	return true
+}
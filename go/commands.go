@@ -0,0 +1,236 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pdxiv/claude-adventure/go/adventure"
+)
+
+// loadGame loads a game in any supported format, chosen by file extension
+func loadGame(filename string, opts adventure.LoadOptions) (*adventure.GameState, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext != ".json" && ext != ".yaml" && ext != ".yml" && ext != ".sck" {
+		return adventure.LoadGameDataFile(filename, opts)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read game file: %w", err)
+	}
+	defer file.Close()
+
+	switch ext {
+	case ".json":
+		return adventure.ImportJSON(file)
+	case ".sck":
+		return adventure.Compile(file, filename)
+	default:
+		return adventure.ImportYAML(file)
+	}
+}
+
+// parseInterspersed parses flags that come before, between or after the
+// positional arguments, so "adventure game.dat -debug" works as it did
+// before the flag package was used. Everything after "--" is positional.
+// It returns the positional arguments.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := flags.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// runConvert converts a game between the .dat, JSON and YAML formats.
+// Adventure source (.sck) is compiled when given as the input.
+func runConvert(args []string) int {
+	if len(args) != 2 {
+		fmt.Println("Usage: adventure convert <input> <output.dat|.json|.yaml>")
+		return 1
+	}
+
+	state, err := loadGame(args[0], adventure.LoadOptions{})
+	if err != nil {
+		fmt.Printf("Error loading game data:\n%v\n", err)
+		return 1
+	}
+
+	file, err := os.Create(args[1])
+	if err != nil {
+		fmt.Printf("Error creating output file: %v\n", err)
+		return 1
+	}
+
+	switch strings.ToLower(filepath.Ext(args[1])) {
+	case ".json":
+		err = adventure.ExportJSON(file, state)
+	case ".yaml", ".yml":
+		err = adventure.ExportYAML(file, state)
+	default:
+		err = adventure.WriteGameData(file, state)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Printf("Error writing %s: %v\n", args[1], err)
+		return 1
+	}
+
+	return 0
+}
+
+// runDecompile writes a game as adventure source, to a file or stdout
+func runDecompile(args []string) int {
+	if len(args) < 1 || len(args) > 2 {
+		fmt.Println("Usage: adventure decompile <game_file> [output.sck]")
+		return 1
+	}
+
+	state, err := loadGame(args[0], adventure.LoadOptions{})
+	if err != nil {
+		fmt.Printf("Error loading game data: %v\n", err)
+		return 1
+	}
+
+	if len(args) == 1 {
+		if err := adventure.Decompile(os.Stdout, state); err != nil {
+			fmt.Printf("Error writing source: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	file, err := os.Create(args[1])
+	if err != nil {
+		fmt.Printf("Error creating output file: %v\n", err)
+		return 1
+	}
+
+	err = adventure.Decompile(file, state)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Printf("Error writing %s: %v\n", args[1], err)
+		return 1
+	}
+
+	return 0
+}
+
+// runValidate checks game files for broken references and reports every
+// problem found
+func runValidate(args []string) int {
+	if len(args) < 1 {
+		fmt.Println("Usage: adventure validate <game_file>...")
+		return 1
+	}
+
+	status := 0
+	for _, filename := range args {
+		state, err := loadGame(filename, adventure.LoadOptions{})
+		if err != nil {
+			fmt.Printf("%s: %v\n", filename, err)
+			status = 1
+			continue
+		}
+
+		problems := adventure.Validate(state)
+		for _, problem := range problems {
+			fmt.Printf("%s: %s\n", filename, problem)
+		}
+		if len(problems) > 0 {
+			fmt.Printf("%s: %d problem(s) found\n", filename, len(problems))
+			status = 1
+		}
+	}
+
+	return status
+}
+
+// runMap writes the room graph of a game as Graphviz DOT or Mermaid, to a
+// file or stdout
+func runMap(args []string) int {
+	flags := flag.NewFlagSet("map", flag.ContinueOnError)
+	format := flags.String("format", "", "map format: dot or mermaid (default from the output file name, else dot)")
+	gotos := flags.Bool("gotos", false, "add edges for actions that move the player (GOTOy)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: adventure map [flags] <game_file> [output.dot|.mmd]")
+		flags.PrintDefaults()
+	}
+	files, err := parseInterspersed(flags, args)
+	if err != nil {
+		return 1
+	}
+	if len(files) < 1 || len(files) > 2 {
+		flags.Usage()
+		return 1
+	}
+	output := "" // Standard output
+	if len(files) == 2 {
+		output = files[1]
+	}
+
+	state, err := loadGame(files[0], adventure.LoadOptions{})
+	if err != nil {
+		fmt.Printf("Error loading game data: %v\n", err)
+		return 1
+	}
+
+	if *format == "" {
+		*format = "dot"
+		if ext := strings.ToLower(filepath.Ext(output)); ext == ".mmd" || ext == ".mermaid" {
+			*format = "mermaid"
+		}
+	}
+	write := adventure.WriteDOT
+	switch *format {
+	case "dot":
+	case "mermaid":
+		write = adventure.WriteMermaid
+	default:
+		fmt.Printf("Unknown map format %q\n", *format)
+		return 1
+	}
+
+	opts := adventure.MapOptions{Gotos: *gotos}
+	if output == "" {
+		if err := write(os.Stdout, state, opts); err != nil {
+			fmt.Printf("Error writing map: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		fmt.Printf("Error creating output file: %v\n", err)
+		return 1
+	}
+
+	err = write(file, state, opts)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Printf("Error writing %s: %v\n", output, err)
+		return 1
+	}
+
+	return 0
+}